@@ -0,0 +1,313 @@
+package pastebin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+var (
+	ErrAliasNotFound      = errors.New("alias not found")
+	ErrAliasPasteNotFound = errors.New("alias paste not found")
+)
+
+// AliasRegistry maps stable names to the current key of pastes, which change every time a paste is updated with
+// UpdatePaste. The aliases are stored either in a local file (see NewFileAliasRegistry) or in a private paste of the
+// authenticated user (see Client.NewPasteAliasRegistry).
+//
+// An AliasRegistry is safe for concurrent use, but multiple processes modifying the same aliases at the same time may
+// overwrite each other's changes.
+type AliasRegistry struct {
+	store aliasStore
+	mutex sync.Mutex
+}
+
+// aliasStore loads and saves the aliases of an AliasRegistry
+type aliasStore interface {
+	load() (map[string]string, error)
+	save(aliases map[string]string) error
+}
+
+// NewFileAliasRegistry creates an AliasRegistry that stores the aliases in a JSON file, which is created on the first
+// modification if it doesn't exist
+func NewFileAliasRegistry(path string) *AliasRegistry {
+	return &AliasRegistry{store: &fileAliasStore{path: path}}
+}
+
+// NewPasteAliasRegistry creates an AliasRegistry that stores the aliases in an existing private paste of the
+// authenticated user with the given title (see CreatePasteAliasRegistry).
+//
+// Since pastes cannot be edited, every modification replaces the paste with a new one. The paste is found by its title
+// the first time the aliases are loaded, and by the key of the paste that replaced it from then on. If no paste has the
+// title, every operation of the registry returns ErrAliasPasteNotFound rather than treating the aliases as empty.
+func (c *Client) NewPasteAliasRegistry(title string) *AliasRegistry {
+	return &AliasRegistry{store: &pasteAliasStore{client: c, title: title}}
+}
+
+// CreatePasteAliasRegistry creates a private paste with no aliases to store the aliases of an AliasRegistry in, unless
+// a paste with the given title already exists, and returns the registry.
+// See NewPasteAliasRegistry
+func (c *Client) CreatePasteAliasRegistry(title string) (*AliasRegistry, error) {
+	store := &pasteAliasStore{client: c, title: title}
+	_, err := store.load()
+	if err == nil {
+		return &AliasRegistry{store: store}, nil
+	}
+	// A paste missing from a truncated list may still exist, in which case creating another one would hide it
+	if !errors.Is(err, ErrAliasPasteNotFound) || errors.Is(err, ErrPasteListTruncated) {
+		return nil, err
+	}
+	if err = store.save(make(map[string]string)); err != nil {
+		return nil, err
+	}
+	return &AliasRegistry{store: store}, nil
+}
+
+// Resolve returns the key of the paste the alias points to, or ErrAliasNotFound if the alias doesn't exist
+func (r *AliasRegistry) Resolve(alias string) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	aliases, err := r.store.load()
+	if err != nil {
+		return "", err
+	}
+	pasteKey, exists := aliases[alias]
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrAliasNotFound, alias)
+	}
+	return pasteKey, nil
+}
+
+// Set makes the alias point to the paste, creating the alias if it doesn't exist
+func (r *AliasRegistry) Set(alias, pasteKey string) error {
+	if len(alias) == 0 {
+		return errors.New("alias must not be empty")
+	}
+	if err := ValidatePasteKey(pasteKey); err != nil {
+		return err
+	}
+	return r.update(func(aliases map[string]string) bool {
+		if aliases[alias] == pasteKey {
+			return false
+		}
+		aliases[alias] = pasteKey
+		return true
+	})
+}
+
+// Remove removes the alias, if it exists
+func (r *AliasRegistry) Remove(alias string) error {
+	return r.update(func(aliases map[string]string) bool {
+		if _, exists := aliases[alias]; !exists {
+			return false
+		}
+		delete(aliases, alias)
+		return true
+	})
+}
+
+// Aliases returns every alias, mapped to the key of the paste it points to
+func (r *AliasRegistry) Aliases() (map[string]string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.store.load()
+}
+
+// replacePasteKey makes every alias pointing to oldPasteKey point to newPasteKey
+func (r *AliasRegistry) replacePasteKey(oldPasteKey, newPasteKey string) error {
+	return r.update(func(aliases map[string]string) bool {
+		modified := false
+		for alias, pasteKey := range aliases {
+			if pasteKey == oldPasteKey {
+				aliases[alias] = newPasteKey
+				modified = true
+			}
+		}
+		return modified
+	})
+}
+
+// update loads the aliases, passes them to modify, and saves them if modify returns true
+func (r *AliasRegistry) update(modify func(aliases map[string]string) bool) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	aliases, err := r.store.load()
+	if err != nil {
+		return err
+	}
+	if !modify(aliases) {
+		return nil
+	}
+	return r.store.save(aliases)
+}
+
+// WithAliases configures the client to update the aliases of the registry when a paste is replaced by UpdatePaste,
+// and to resolve aliases in ResolvePaste and UpdateAlias. Passing nil removes the registry.
+//
+// Returns the client to allow chaining
+func (c *Client) WithAliases(registry *AliasRegistry) *Client {
+	c.aliases = registry
+	return c
+}
+
+// ResolvePaste returns the key of the paste referenced by ref, which may be an alias of the AliasRegistry configured
+// with WithAliases, a paste key or a link to a paste (see ParsePasteRef)
+func (c *Client) ResolvePaste(ref string) (string, error) {
+	if c.aliases != nil {
+		pasteKey, err := c.aliases.Resolve(ref)
+		if err == nil {
+			return pasteKey, nil
+		}
+		if !errors.Is(err, ErrAliasNotFound) {
+			return "", err
+		}
+	}
+	pasteRef, err := c.URLBuilder().ParsePasteRef(ref)
+	if err != nil {
+		return "", err
+	}
+	return pasteRef.Key, nil
+}
+
+// UpdateAlias replaces the content of the paste an alias points to with UpdatePaste, which updates the alias to point
+// to the new paste. Requires an AliasRegistry to be configured with WithAliases.
+func (c *Client) UpdateAlias(alias, content string) (string, error) {
+	if c.aliases == nil {
+		return "", errors.New("no alias registry configured, see WithAliases")
+	}
+	pasteKey, err := c.aliases.Resolve(alias)
+	if err != nil {
+		return "", err
+	}
+	return c.UpdatePaste(pasteKey, content)
+}
+
+type fileAliasStore struct {
+	path string
+}
+
+func (s *fileAliasStore) load() (map[string]string, error) {
+	aliases := make(map[string]string)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return aliases, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("invalid alias file %s: %w", s.path, err)
+	}
+	if aliases == nil {
+		aliases = make(map[string]string)
+	}
+	return aliases, nil
+}
+
+func (s *fileAliasStore) save(aliases map[string]string) error {
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(s.path, data)
+}
+
+type pasteAliasStore struct {
+	client *Client
+	title  string
+
+	// pasteKey is the key of the paste the aliases were last loaded from or saved to
+	pasteKey string
+}
+
+// pasteTitle returns the title of the alias paste, which the policy of the client may have rewritten
+func (s *pasteAliasStore) pasteTitle() string {
+	if s.client.policy == nil {
+		return s.title
+	}
+	request, err := s.client.policy.Apply(NewCreatePasteRequest(s.title, "{}", ExpirationNever, VisibilityPrivate, "json"))
+	if err != nil {
+		return s.title
+	}
+	return request.Title
+}
+
+// findPaste returns the key of the most recent paste titled after the store
+func (s *pasteAliasStore) findPaste() (string, error) {
+	pastes, truncated, err := s.client.getAllUserPastes()
+	if err != nil {
+		return "", err
+	}
+	title := s.pasteTitle()
+	var latest *Paste
+	for _, paste := range pastes {
+		if paste.Title == title && (latest == nil || paste.Date.After(latest.Date)) {
+			latest = paste
+		}
+	}
+	switch {
+	case latest != nil:
+		return latest.Key, nil
+	case truncated:
+		return "", fmt.Errorf("%w: %s is not among the %d most recent pastes: %w", ErrAliasPasteNotFound, title, MaxUserPastes, ErrPasteListTruncated)
+	default:
+		return "", fmt.Errorf("%w: %s", ErrAliasPasteNotFound, title)
+	}
+}
+
+func (s *pasteAliasStore) load() (map[string]string, error) {
+	if len(s.pasteKey) > 0 {
+		content, err := s.client.getUserPasteContent(s.pasteKey)
+		if err == nil {
+			return s.parse(s.pasteKey, content)
+		}
+		if !errors.Is(err, ErrPasteNotOwned) {
+			return nil, err
+		}
+		// The paste was replaced by another client in the meantime, so it has to be found by its title again
+	}
+	pasteKey, err := s.findPaste()
+	if err != nil {
+		return nil, err
+	}
+	content, err := s.client.GetUserPasteContent(pasteKey)
+	if err != nil {
+		return nil, err
+	}
+	s.pasteKey = pasteKey
+	return s.parse(pasteKey, content)
+}
+
+func (s *pasteAliasStore) parse(pasteKey, content string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	if err := json.Unmarshal([]byte(content), &aliases); err != nil {
+		return nil, fmt.Errorf("invalid alias paste %s: %w", pasteKey, err)
+	}
+	if aliases == nil {
+		aliases = make(map[string]string)
+	}
+	return aliases, nil
+}
+
+func (s *pasteAliasStore) save(aliases map[string]string) error {
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return err
+	}
+	pasteKey, err := s.client.CreatePaste(NewCreatePasteRequest(s.title, string(data), ExpirationNever, VisibilityPrivate, "json"))
+	if err != nil {
+		return err
+	}
+	previousPasteKey := s.pasteKey
+	s.pasteKey = pasteKey
+	if len(previousPasteKey) == 0 {
+		return nil
+	}
+	if err = s.client.DeletePaste(previousPasteKey); err != nil {
+		return fmt.Errorf("failed to delete previous alias paste %s: %w", previousPasteKey, err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package pastebin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testAliasRegistry(t *testing.T, registry *AliasRegistry) {
+	if _, err := registry.Resolve("runbook"); !errors.Is(err, ErrAliasNotFound) {
+		t.Error("expected ErrAliasNotFound, got", err)
+	}
+	if err := registry.Set("runbook", "../../etc"); !errors.Is(err, ErrInvalidPasteKey) {
+		t.Error("expected ErrInvalidPasteKey, got", err)
+	}
+	if err := registry.Set("runbook", "abcdefgh"); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if err := registry.Set("other", "ijklmnop"); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if pasteKey, err := registry.Resolve("runbook"); err != nil || pasteKey != "abcdefgh" {
+		t.Errorf("expected abcdefgh, got %s (%v)", pasteKey, err)
+	}
+	if err := registry.Remove("other"); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if aliases, err := registry.Aliases(); err != nil || len(aliases) != 1 || aliases["runbook"] != "abcdefgh" {
+		t.Errorf("unexpected aliases %v (%v)", aliases, err)
+	}
+}
+
+func TestFileAliasRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aliases.json")
+	testAliasRegistry(t, NewFileAliasRegistry(path))
+	if pasteKey, _ := NewFileAliasRegistry(path).Resolve("runbook"); pasteKey != "abcdefgh" {
+		t.Error("the aliases should have been persisted, got", pasteKey)
+	}
+	_ = os.WriteFile(path, []byte("not json"), 0o644)
+	if _, err := NewFileAliasRegistry(path).Resolve("runbook"); err == nil {
+		t.Error("should've returned an error, because the file is invalid")
+	}
+}
+
+func TestPasteAliasRegistry(t *testing.T) {
+	account := newMockAccount()
+	account.pastes["paste001"] = &mockAccountPaste{title: "runbook", content: "v1", syntax: "text", date: 1600000000}
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	if err := client.NewPasteAliasRegistry("aliases").Set("runbook", "abcdefgh"); !errors.Is(err, ErrAliasPasteNotFound) {
+		t.Fatal("expected ErrAliasPasteNotFound, got", err)
+	}
+	registry, err := client.CreatePasteAliasRegistry("aliases")
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	testAliasRegistry(t, registry)
+	var aliasPastes []*mockAccountPaste
+	for _, paste := range account.pastes {
+		if paste.title == "aliases" {
+			aliasPastes = append(aliasPastes, paste)
+		}
+	}
+	if len(aliasPastes) != 1 {
+		t.Fatalf("expected previous alias pastes to have been deleted, got %d alias pastes", len(aliasPastes))
+	}
+	if aliasPastes[0].visibility != int(VisibilityPrivate) {
+		t.Error("the alias paste should be private")
+	}
+	if pasteKey, _ := client.NewPasteAliasRegistry("aliases").Resolve("runbook"); pasteKey != "abcdefgh" {
+		t.Error("the aliases should have been persisted, got", pasteKey)
+	}
+	if _, err = client.CreatePasteAliasRegistry("aliases"); err != nil || account.created != 4 {
+		t.Errorf("the existing alias paste should have been used, got %d pastes created (%v)", account.created, err)
+	}
+}
+
+func TestPasteAliasRegistryWithRewritingPolicy(t *testing.T) {
+	account := newMockAccount()
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	client.WithPolicy(&Policy{RequiredTitlePrefix: "team/", Mode: PolicyModeRewrite})
+	registry, err := client.CreatePasteAliasRegistry("aliases")
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if err = registry.Set("runbook", "abcdefgh"); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if pasteKey, err := client.NewPasteAliasRegistry("aliases").Resolve("runbook"); err != nil || pasteKey != "abcdefgh" {
+		t.Errorf("the alias paste should have been found by its rewritten title, got %s (%v)", pasteKey, err)
+	}
+}
+
+func TestPasteAliasRegistryWithTruncatedList(t *testing.T) {
+	account := newMockAccount()
+	for i := 0; i < MaxUserPastes; i++ {
+		account.pastes[fmt.Sprintf("paste%04d", i)] = &mockAccountPaste{title: "paste", content: "content", date: 1600000000}
+	}
+	account.pastes["aliases1"] = &mockAccountPaste{title: "aliases", content: `{"runbook":"abcdefgh"}`, syntax: "json", date: 1500000000}
+	account.unlisted["aliases1"] = true
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	err := client.NewPasteAliasRegistry("aliases").Set("other", "ijklmnop")
+	if !errors.Is(err, ErrAliasPasteNotFound) || !errors.Is(err, ErrPasteListTruncated) {
+		t.Error("expected ErrAliasPasteNotFound and ErrPasteListTruncated, got", err)
+	}
+	if _, err = client.CreatePasteAliasRegistry("aliases"); !errors.Is(err, ErrPasteListTruncated) {
+		t.Error("expected ErrPasteListTruncated, got", err)
+	}
+	if account.pastes["aliases1"].content != `{"runbook":"abcdefgh"}` || account.created != 0 {
+		t.Error("the aliases shouldn't have been modified")
+	}
+}
+
+func TestClient_ResolvePaste(t *testing.T) {
+	client := &Client{}
+	if pasteKey, err := client.ResolvePaste("https://pastebin.com/raw/abcdefgh"); err != nil || pasteKey != "abcdefgh" {
+		t.Errorf("expected abcdefgh, got %s (%v)", pasteKey, err)
+	}
+	registry := NewFileAliasRegistry(filepath.Join(t.TempDir(), "aliases.json"))
+	_ = registry.Set("runbook", "ijklmnop")
+	client.WithAliases(registry)
+	if pasteKey, err := client.ResolvePaste("runbook"); err != nil || pasteKey != "ijklmnop" {
+		t.Errorf("expected ijklmnop, got %s (%v)", pasteKey, err)
+	}
+	if pasteKey, err := client.ResolvePaste("abcdefgh"); err != nil || pasteKey != "abcdefgh" {
+		t.Errorf("expected abcdefgh, got %s (%v)", pasteKey, err)
+	}
+	if _, err := client.ResolvePaste("not a key"); err == nil {
+		t.Error("should've returned an error")
+	}
+}
@@ -0,0 +1,124 @@
+package pastebin
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ArchiveFormat is the format of the archives written by Backup. RestoreArchive detects the format automatically.
+type ArchiveFormat string
+
+const (
+	ArchiveFormatTar ArchiveFormat = "tar"
+	ArchiveFormatZip ArchiveFormat = "zip"
+)
+
+// archiveWriter writes files to an archive
+type archiveWriter interface {
+	writeFile(name string, data []byte, modTime time.Time) error
+	Close() error
+}
+
+func newArchiveWriter(w io.Writer, format ArchiveFormat) (archiveWriter, error) {
+	switch format {
+	case ArchiveFormatTar, "":
+		return &tarArchiveWriter{writer: tar.NewWriter(w)}, nil
+	case ArchiveFormatZip:
+		return &zipArchiveWriter{writer: zip.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive format %q", format)
+	}
+}
+
+type tarArchiveWriter struct {
+	writer *tar.Writer
+}
+
+func (aw *tarArchiveWriter) writeFile(name string, data []byte, modTime time.Time) error {
+	if err := aw.writer.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: modTime, Typeflag: tar.TypeReg}); err != nil {
+		return err
+	}
+	_, err := aw.writer.Write(data)
+	return err
+}
+
+func (aw *tarArchiveWriter) Close() error {
+	return aw.writer.Close()
+}
+
+type zipArchiveWriter struct {
+	writer *zip.Writer
+}
+
+func (aw *zipArchiveWriter) writeFile(name string, data []byte, modTime time.Time) error {
+	fileWriter, err := aw.writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime})
+	if err != nil {
+		return err
+	}
+	_, err = fileWriter.Write(data)
+	return err
+}
+
+func (aw *zipArchiveWriter) Close() error {
+	return aw.writer.Close()
+}
+
+// zipSignature is the signature at the beginning of zip archives
+var zipSignature = []byte("PK\x03\x04")
+
+// readArchiveFiles reads every regular file of a tar or zip archive, detecting the format from the content
+func readArchiveFiles(r io.Reader) (map[string][]byte, error) {
+	bufferedReader := bufio.NewReader(r)
+	signature, _ := bufferedReader.Peek(len(zipSignature))
+	files := make(map[string][]byte)
+	if bytes.Equal(signature, zipSignature) {
+		// Zip archives must be read from the end, so they have to be read entirely first
+		data, err := io.ReadAll(bufferedReader)
+		if err != nil {
+			return nil, err
+		}
+		zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range zipReader.File {
+			if file.FileInfo().IsDir() {
+				continue
+			}
+			fileReader, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			content, err := io.ReadAll(fileReader)
+			_ = fileReader.Close()
+			if err != nil {
+				return nil, err
+			}
+			files[file.Name] = content
+		}
+		return files, nil
+	}
+	tarReader := tar.NewReader(bufferedReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		files[header.Name] = content
+	}
+}
@@ -0,0 +1,64 @@
+package pastebin
+
+import (
+	"bytes"
+	"strings"
+)
+
+const armoredLineLength = 64
+
+// formatArmorHeaders formats the headers as "Name: value" lines in the given order, so that the output is deterministic
+func formatArmorHeaders(headers map[string]string, order []string) string {
+	var lines []string
+	for _, name := range order {
+		if value, exists := headers[name]; exists {
+			lines = append(lines, name+": "+value)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseArmorHeaders parses the "Name: value" lines of a header block
+func parseArmorHeaders(headerBlock string) map[string]string {
+	headers := make(map[string]string)
+	for _, line := range strings.Split(headerBlock, "\n") {
+		if name, value, found := strings.Cut(line, ":"); found {
+			headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+	return headers
+}
+
+// armorPayload wraps the headers and the encoded payload between the header and footer lines, with the payload
+// split into lines of armoredLineLength characters
+func armorPayload(header, footer, headerBlock, encodedPayload string) string {
+	var buffer bytes.Buffer
+	buffer.WriteString(header + "\n")
+	buffer.WriteString(headerBlock + "\n\n")
+	for len(encodedPayload) > armoredLineLength {
+		buffer.WriteString(encodedPayload[:armoredLineLength] + "\n")
+		encodedPayload = encodedPayload[armoredLineLength:]
+	}
+	if len(encodedPayload) > 0 {
+		buffer.WriteString(encodedPayload + "\n")
+	}
+	buffer.WriteString(footer + "\n")
+	return buffer.String()
+}
+
+// dearmor is the reverse of armorPayload, returning the header block and the encoded payload without line breaks.
+// Returns false if the armored text is malformed.
+func dearmor(header, footer, armored string) (string, string, bool) {
+	armored = strings.ReplaceAll(armored, "\r\n", "\n")
+	start := strings.Index(armored, header)
+	end := strings.Index(armored, footer)
+	if start == -1 || end == -1 || end < start {
+		return "", "", false
+	}
+	body := strings.Trim(armored[start+len(header):end], "\n")
+	headerBlock, encodedPayload, found := strings.Cut(body, "\n\n")
+	if !found {
+		return "", "", false
+	}
+	return headerBlock, strings.Join(strings.Fields(encodedPayload), ""), true
+}
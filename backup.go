@@ -0,0 +1,224 @@
+package pastebin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	backupManifestFormat  = "go-pastebin/backup-manifest"
+	backupManifestVersion = 1
+
+	// BackupManifestFileName is the name of the manifest in archives written by Backup
+	BackupManifestFileName = "manifest.json"
+
+	// backupPastesDirectory is the directory containing the content of the pastes in archives written by Backup
+	backupPastesDirectory = "pastes/"
+)
+
+// BackupManifest is the manifest of an archive written by Backup, which lists every paste in the archive
+type BackupManifest struct {
+	Format    string         `json:"format"`
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	Pastes    []*BackupPaste `json:"pastes"`
+}
+
+// BackupPaste is the metadata of a paste in a BackupManifest
+type BackupPaste struct {
+	Key   string    `json:"key"`
+	Title string    `json:"title"`
+	User  string    `json:"user"`
+	URL   string    `json:"url"`
+	Hits  int       `json:"hits"`
+	Size  int       `json:"size"`
+	Date  time.Time `json:"date"`
+
+	// ExpireDate is the expiration date of the paste, which is the Unix epoch if the paste never expires
+	ExpireDate time.Time  `json:"expire_date"`
+	Visibility Visibility `json:"visibility"`
+	Syntax     string     `json:"syntax"`
+
+	// File is the path of the file containing the content of the paste in the archive
+	File string `json:"file"`
+
+	// SHA256 is the hex-encoded SHA-256 checksum of the content of the paste
+	SHA256 string `json:"sha256"`
+}
+
+func newBackupPaste(paste *Paste, content string) *BackupPaste {
+	checksum := sha256.Sum256([]byte(content))
+	return &BackupPaste{
+		Key:        paste.Key,
+		Title:      paste.Title,
+		User:       paste.User,
+		URL:        paste.URL,
+		Hits:       paste.Hits,
+		Size:       paste.Size,
+		Date:       paste.Date,
+		ExpireDate: paste.ExpireDate,
+		Visibility: paste.Visibility,
+		Syntax:     paste.Syntax,
+		File:       backupPastesDirectory + paste.Key + ".txt",
+		SHA256:     hex.EncodeToString(checksum[:]),
+	}
+}
+
+// ToPaste converts the metadata of the paste to a Paste
+func (bp *BackupPaste) ToPaste() *Paste {
+	return &Paste{
+		Key:        bp.Key,
+		Title:      bp.Title,
+		User:       bp.User,
+		URL:        bp.URL,
+		Hits:       bp.Hits,
+		Size:       bp.Size,
+		Date:       bp.Date,
+		ExpireDate: bp.ExpireDate,
+		Visibility: bp.Visibility,
+		Syntax:     bp.Syntax,
+	}
+}
+
+// BackupOptions are the options of BackupWithOptions
+type BackupOptions struct {
+	// Format is the format of the archive. Defaults to ArchiveFormatTar.
+	Format ArchiveFormat
+
+	// Concurrency is the maximum number of pastes downloaded at the same time. Defaults to DefaultConcurrency.
+	Concurrency int
+
+	// WorkDir is the directory in which the content of every paste is stored as soon as it's downloaded.
+	// If a backup fails, running it again with the same WorkDir only downloads the pastes that are missing.
+	// The directory is created if necessary, and is not removed once the backup is complete.
+	WorkDir string
+
+	// Progress, if set, is called every time a paste has been downloaded, read from WorkDir or failed.
+	// It is never called concurrently.
+	Progress func(progress BackupProgress)
+}
+
+// BackupProgress is the progress of a backup, as reported to BackupOptions.Progress
+type BackupProgress struct {
+	// PasteKey is the key of the paste that was processed
+	PasteKey string
+
+	// Completed is the number of pastes processed so far, including this one, and Total is the number of pastes
+	Completed, Total int
+
+	// Resumed is whether the content of the paste was read from WorkDir rather than downloaded
+	Resumed bool
+
+	// Err is the error that prevented the content of the paste from being retrieved, if any
+	Err error
+}
+
+// Backup writes every paste of the authenticated user to w as a tar archive.
+// See BackupWithOptions for more information.
+func (c *Client) Backup(w io.Writer) (*BackupManifest, error) {
+	return c.BackupWithOptions(w, BackupOptions{})
+}
+
+// BackupWithOptions writes every paste of the authenticated user to w as an archive containing the content of each
+// paste in the pastes directory, as well as a manifest.json file containing the metadata of every paste.
+//
+// The MaxUserPastes most recent pastes are listed, and their content is retrieved with GetUserPasteContent. The content of
+// each paste is written to w as soon as it's retrieved, and the manifest is written last. If the list of pastes is
+// truncated, an error wrapping ErrPasteListTruncated is returned before anything is written to w.
+//
+// If the content of any paste cannot be retrieved, a *BulkError is returned and the archive written to w is incomplete
+// and has no manifest, so it must be discarded. Using BackupOptions.WorkDir allows the backup to be resumed without
+// downloading every paste again.
+func (c *Client) BackupWithOptions(w io.Writer, options BackupOptions) (*BackupManifest, error) {
+	archive, err := newArchiveWriter(w, options.Format)
+	if err != nil {
+		return nil, err
+	}
+	pastes, truncated, err := c.getAllUserPastes()
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		return nil, fmt.Errorf("%w: only the %d most recent pastes can be backed up", ErrPasteListTruncated, len(pastes))
+	}
+	if len(options.WorkDir) > 0 {
+		if err = os.MkdirAll(options.WorkDir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+	pastesByKey := make(map[string]*Paste, len(pastes))
+	pasteKeys := make([]string, 0, len(pastes))
+	for _, paste := range pastes {
+		pastesByKey[paste.Key] = paste
+		pasteKeys = append(pasteKeys, paste.Key)
+	}
+	var mutex sync.Mutex
+	var completed int
+	backupPastes, err := runBulk(pasteKeys, options.Concurrency, func(pasteKey string) (*BackupPaste, error) {
+		content, resumed, err := c.retrieveBackupContent(pasteKey, options.WorkDir)
+		mutex.Lock()
+		defer mutex.Unlock()
+		var backupPaste *BackupPaste
+		if err == nil {
+			backupPaste = newBackupPaste(pastesByKey[pasteKey], content)
+			err = archive.writeFile(backupPaste.File, []byte(content), pastesByKey[pasteKey].Date)
+		}
+		if options.Progress != nil {
+			completed++
+			options.Progress(BackupProgress{PasteKey: pasteKey, Completed: completed, Total: len(pasteKeys), Resumed: resumed, Err: err})
+		}
+		return backupPaste, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	manifest := &BackupManifest{Format: backupManifestFormat, Version: backupManifestVersion, CreatedAt: time.Now().UTC()}
+	for _, paste := range pastes {
+		manifest.Pastes = append(manifest.Pastes, backupPastes[paste.Key].Value)
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err = archive.writeFile(BackupManifestFileName, manifestData, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err = archive.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// retrieveBackupContent returns the content of the paste from the work directory if it was already downloaded, or
+// downloads it and stores it in the work directory otherwise
+func (c *Client) retrieveBackupContent(pasteKey, workDir string) (content string, resumed bool, err error) {
+	if len(workDir) == 0 {
+		content, err = c.GetUserPasteContent(pasteKey)
+		return content, false, err
+	}
+	// The key is used as a file name, so it must not be able to escape the work directory
+	if err = ValidatePasteKey(pasteKey); err != nil {
+		return "", false, err
+	}
+	path := filepath.Join(workDir, pasteKey+".txt")
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return string(data), true, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", false, err
+	}
+	if content, err = c.GetUserPasteContent(pasteKey); err != nil {
+		return "", false, err
+	}
+	return content, false, writeFileAtomically(path, []byte(content))
+}
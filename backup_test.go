@@ -0,0 +1,228 @@
+package pastebin
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+// mockAccount is a fake Pastebin account that supports logging in, listing, retrieving, checking, creating and
+// deleting pastes
+type mockAccount struct {
+	pastes   map[string]*mockAccountPaste
+	failKeys map[string]bool
+
+	// unlisted are the keys of the pastes that are left out of the list, as if the list had been truncated
+	unlisted map[string]bool
+	created  int
+	mutex    sync.Mutex
+}
+
+type mockAccountPaste struct {
+	title, content, syntax, expiration string
+	visibility                         int
+	date                               int64
+}
+
+func newMockAccount() *mockAccount {
+	return &mockAccount{pastes: make(map[string]*mockAccountPaste), failKeys: make(map[string]bool), unlisted: make(map[string]bool)}
+}
+
+func (ma *mockAccount) httpClient() *http.Client {
+	return &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		ma.mutex.Lock()
+		defer ma.mutex.Unlock()
+		var fields url.Values
+		if request.Body != nil {
+			body, _ := io.ReadAll(request.Body)
+			fields, _ = url.ParseQuery(string(body))
+		}
+		respond := func(body string) *http.Response {
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString(body))}
+		}
+		if request.URL.String() == LoginApiUrl {
+			return respond("session-key")
+		}
+		if request.Method == "HEAD" && strings.HasPrefix(request.URL.String(), RawUrlPrefix+"/") {
+			paste, exists := ma.pastes[strings.TrimPrefix(request.URL.String(), RawUrlPrefix+"/")]
+			switch {
+			case !exists:
+				return &http.Response{StatusCode: 404, Body: io.NopCloser(bytes.NewBufferString(""))}
+			case paste.visibility == int(VisibilityPrivate):
+				return &http.Response{StatusCode: 401, Body: io.NopCloser(bytes.NewBufferString(""))}
+			}
+			return respond("")
+		}
+		pasteKey := fields.Get("api_paste_key")
+		switch fields.Get("api_option") {
+		case "list":
+			var output strings.Builder
+			keys := make([]string, 0, len(ma.pastes))
+			for key := range ma.pastes {
+				if !ma.unlisted[key] {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				paste := ma.pastes[key]
+				fmt.Fprintf(&output, "<paste><paste_key>%s</paste_key><paste_date>%d</paste_date><paste_title>%s</paste_title><paste_size>%d</paste_size><paste_expire_date>0</paste_expire_date><paste_private>%d</paste_private><paste_format_short>%s</paste_format_short><paste_url>https://pastebin.com/%s</paste_url><paste_hits>3</paste_hits></paste>", key, paste.date, paste.title, len(paste.content), paste.visibility, paste.syntax, key)
+			}
+			return respond(output.String())
+		case "show_paste":
+			if paste, exists := ma.pastes[pasteKey]; exists && !ma.failKeys[pasteKey] {
+				return respond(paste.content)
+			}
+			return respond("Bad API request, invalid permission to view this paste or invalid api_paste_key")
+		case "paste":
+			ma.created++
+			key := fmt.Sprintf("new%05d", ma.created)
+			visibility := 0
+			fmt.Sscanf(fields.Get("api_paste_private"), "%d", &visibility)
+			ma.pastes[key] = &mockAccountPaste{title: fields.Get("api_paste_name"), content: fields.Get("api_paste_code"), syntax: fields.Get("api_paste_format"), expiration: fields.Get("api_paste_expire_date"), visibility: visibility, date: 1700000000}
+			return respond("https://pastebin.com/" + key)
+		case "delete":
+			if _, exists := ma.pastes[pasteKey]; !exists {
+				return respond("Bad API request, invalid permission to remove paste")
+			}
+			delete(ma.pastes, pasteKey)
+			return respond("Paste Removed")
+		}
+		return &http.Response{StatusCode: 404, Body: io.NopCloser(bytes.NewBufferString("Not Found"))}
+	})}
+}
+
+func readTestArchive(t *testing.T, data []byte, format ArchiveFormat) map[string]string {
+	files := make(map[string]string)
+	if format == ArchiveFormatZip {
+		reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range reader.File {
+			fileReader, _ := file.Open()
+			content, _ := io.ReadAll(fileReader)
+			files[file.Name] = string(content)
+		}
+		return files
+	}
+	reader := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(reader)
+		files[header.Name] = string(content)
+	}
+}
+
+func TestClient_Backup(t *testing.T) {
+	account := newMockAccount()
+	account.pastes["paste001"] = &mockAccountPaste{title: "first", content: "content 1", syntax: "go", visibility: 2, date: 1600000000}
+	account.pastes["paste002"] = &mockAccountPaste{title: "second", content: "content 2", syntax: "text", visibility: 0, date: 1600000001}
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	for _, format := range []ArchiveFormat{ArchiveFormatTar, ArchiveFormatZip} {
+		t.Run(string(format), func(t *testing.T) {
+			var buffer bytes.Buffer
+			manifest, err := client.BackupWithOptions(&buffer, BackupOptions{Format: format})
+			if err != nil {
+				t.Fatal("shouldn't have returned an error, got", err)
+			}
+			files := readTestArchive(t, buffer.Bytes(), format)
+			if len(files) != 3 || files["pastes/paste001.txt"] != "content 1" || files["pastes/paste002.txt"] != "content 2" {
+				t.Errorf("unexpected files %v", files)
+			}
+			var archivedManifest BackupManifest
+			if err = json.Unmarshal([]byte(files[BackupManifestFileName]), &archivedManifest); err != nil {
+				t.Fatal("manifest should be valid JSON, got", err)
+			}
+			if len(archivedManifest.Pastes) != 2 || len(manifest.Pastes) != 2 {
+				t.Fatalf("expected 2 pastes in the manifest, got %d", len(archivedManifest.Pastes))
+			}
+			paste := archivedManifest.Pastes[0]
+			if paste.Key != "paste001" || paste.Title != "first" || paste.Syntax != "go" || paste.Visibility != VisibilityPrivate || paste.Hits != 3 || paste.Date.Unix() != 1600000000 || paste.User != "username" {
+				t.Errorf("unexpected paste in manifest %+v", paste)
+			}
+		})
+	}
+}
+
+func TestClient_BackupWithWorkDirResumesAfterFailure(t *testing.T) {
+	account := newMockAccount()
+	account.pastes["paste001"] = &mockAccountPaste{title: "first", content: "content 1"}
+	account.pastes["paste002"] = &mockAccountPaste{title: "second", content: "content 2"}
+	account.failKeys["paste002"] = true
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	workDir := t.TempDir()
+	var buffer bytes.Buffer
+	var progress []BackupProgress
+	options := BackupOptions{WorkDir: workDir, Concurrency: 1, Progress: func(p BackupProgress) {
+		progress = append(progress, p)
+	}}
+	_, err := client.BackupWithOptions(&buffer, options)
+	var bulkErr *BulkError
+	if !errors.As(err, &bulkErr) || len(bulkErr.Errs) != 1 {
+		t.Fatalf("expected a *BulkError with 1 error, got %v", err)
+	}
+	if _, _, err = readBackupArchive(bytes.NewReader(buffer.Bytes())); !errors.Is(err, ErrInvalidBackupArchive) {
+		t.Errorf("the incomplete archive should've had no manifest, got %v", err)
+	}
+	if len(progress) != 2 || progress[1].Completed != 2 || progress[1].Total != 2 || progress[1].Err == nil {
+		t.Errorf("unexpected progress %+v", progress)
+	}
+	// Once the paste can be retrieved again, the backup can be resumed
+	account.failKeys["paste002"] = false
+	account.pastes["paste001"].content = "should not be downloaded again"
+	progress = nil
+	buffer.Reset()
+	if _, err = client.BackupWithOptions(&buffer, options); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if len(progress) != 2 || !progress[0].Resumed || progress[1].Resumed {
+		t.Errorf("expected only the first paste to be resumed, got %+v", progress)
+	}
+	if files := readTestArchive(t, buffer.Bytes(), ArchiveFormatTar); files["pastes/paste001.txt"] != "content 1" || files["pastes/paste002.txt"] != "content 2" {
+		t.Errorf("unexpected files %v", files)
+	}
+}
+
+func TestClient_BackupWithTruncatedList(t *testing.T) {
+	account := newMockAccount()
+	for i := 0; i < MaxUserPastes; i++ {
+		account.pastes[fmt.Sprintf("paste%04d", i)] = &mockAccountPaste{title: "title", content: "content"}
+	}
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	var buffer bytes.Buffer
+	if _, err := client.Backup(&buffer); !errors.Is(err, ErrPasteListTruncated) {
+		t.Fatalf("expected %v, got %v", ErrPasteListTruncated, err)
+	}
+	if buffer.Len() != 0 {
+		t.Error("nothing should've been written to the writer")
+	}
+}
+
+func TestClient_BackupWithoutCredentials(t *testing.T) {
+	client, _ := NewClient("", "", "token")
+	if _, err := client.Backup(io.Discard); err != ErrNotAuthenticated {
+		t.Errorf("expected %v, got %v", ErrNotAuthenticated, err)
+	}
+}
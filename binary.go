@@ -0,0 +1,257 @@
+package pastebin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/ascii85"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+const (
+	binaryPayloadHeader  = "-----BEGIN PASTEBIN BINARY PAYLOAD-----"
+	binaryPayloadFooter  = "-----END PASTEBIN BINARY PAYLOAD-----"
+	binaryPayloadVersion = 1
+)
+
+var (
+	ErrInvalidBinaryPayload     = errors.New("paste is not a valid binary payload")
+	ErrUnsupportedCompression   = errors.New("unsupported compression")
+	ErrUnsupportedArmor         = errors.New("unsupported armor")
+	ErrBinaryChecksumMismatch   = errors.New("checksum of the decoded binary payload does not match the checksum in its header")
+	ErrBinaryPayloadSizeInvalid = errors.New("size of the decoded binary payload does not match the size in its header")
+)
+
+// Compression is the compression algorithm applied to a binary payload before it is armored
+type Compression string
+
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+
+	// CompressionZstd is not supported out of the box in order to avoid pulling a dependency.
+	// To use it, you must first register a Compressor for it with RegisterCompressor.
+	CompressionZstd Compression = "zstd"
+)
+
+// Armor is the text encoding used to make a binary payload safe to store in a paste
+type Armor string
+
+const (
+	ArmorBase64  Armor = "base64"
+	ArmorASCII85 Armor = "ascii85"
+)
+
+// Compressor compresses and decompresses binary payloads
+type Compressor interface {
+	// NewWriter returns a writer that compresses everything written to it into w
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+
+	// NewReader returns a reader that decompresses r
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, gzip.BestCompression)
+}
+
+func (gzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+var (
+	compressors      = map[Compression]Compressor{CompressionGzip: gzipCompressor{}}
+	compressorsMutex sync.RWMutex
+)
+
+// RegisterCompressor registers a Compressor for the given compression, which allows the use of compression algorithms
+// that aren't supported out of the box, such as CompressionZstd
+func RegisterCompressor(compression Compression, compressor Compressor) {
+	compressorsMutex.Lock()
+	defer compressorsMutex.Unlock()
+	compressors[compression] = compressor
+}
+
+func getCompressor(compression Compression) (Compressor, error) {
+	compressorsMutex.RLock()
+	defer compressorsMutex.RUnlock()
+	compressor, exists := compressors[compression]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCompression, compression)
+	}
+	return compressor, nil
+}
+
+// BinaryPayload is a decoded binary payload
+type BinaryPayload struct {
+	// Name is the original name of the data (e.g. logs.tar)
+	Name string
+
+	// Data is the original data, after decompression
+	Data []byte
+
+	// Compression is the compression that was used to encode the payload
+	Compression Compression
+
+	// Armor is the text encoding that was used to encode the payload
+	Armor Armor
+}
+
+// EncodeBinaryPayload compresses and armors arbitrary bytes so that they can be stored in a paste
+//
+// The header of the resulting text records the name, the size and the SHA-256 checksum of the original data.
+// If compression is empty, CompressionGzip is used. If armor is empty, ArmorBase64 is used.
+func EncodeBinaryPayload(name string, data []byte, compression Compression, armor Armor) (string, error) {
+	if len(compression) == 0 {
+		compression = CompressionGzip
+	}
+	if len(armor) == 0 {
+		armor = ArmorBase64
+	}
+	compressed := data
+	if compression != CompressionNone {
+		compressor, err := getCompressor(compression)
+		if err != nil {
+			return "", err
+		}
+		var buffer bytes.Buffer
+		writer, err := compressor.NewWriter(&buffer)
+		if err != nil {
+			return "", err
+		}
+		if _, err = writer.Write(data); err != nil {
+			return "", err
+		}
+		if err = writer.Close(); err != nil {
+			return "", err
+		}
+		compressed = buffer.Bytes()
+	}
+	var encoded string
+	switch armor {
+	case ArmorBase64:
+		encoded = base64.StdEncoding.EncodeToString(compressed)
+	case ArmorASCII85:
+		buffer := make([]byte, ascii85.MaxEncodedLen(len(compressed)))
+		encoded = string(buffer[:ascii85.Encode(buffer, compressed)])
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedArmor, armor)
+	}
+	checksum := sha256.Sum256(data)
+	headers := map[string]string{
+		"Version":     strconv.Itoa(binaryPayloadVersion),
+		"Name":        strconv.Quote(name),
+		"Size":        strconv.Itoa(len(data)),
+		"SHA256":      hex.EncodeToString(checksum[:]),
+		"Compression": string(compression),
+		"Armor":       string(armor),
+	}
+	headerBlock := formatArmorHeaders(headers, []string{"Version", "Name", "Size", "SHA256", "Compression", "Armor"})
+	return armorPayload(binaryPayloadHeader, binaryPayloadFooter, headerBlock, encoded), nil
+}
+
+// DecodeBinaryPayload is the reverse of EncodeBinaryPayload
+//
+// The size and the checksum of the decoded data are verified against the header.
+func DecodeBinaryPayload(text string) (*BinaryPayload, error) {
+	headerBlock, encoded, ok := dearmor(binaryPayloadHeader, binaryPayloadFooter, text)
+	if !ok {
+		return nil, ErrInvalidBinaryPayload
+	}
+	headers := parseArmorHeaders(headerBlock)
+	if headers["Version"] != strconv.Itoa(binaryPayloadVersion) {
+		return nil, fmt.Errorf("%w: unsupported version %s", ErrInvalidBinaryPayload, headers["Version"])
+	}
+	name, err := strconv.Unquote(headers["Name"])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid name", ErrInvalidBinaryPayload)
+	}
+	size, err := strconv.ParseInt(headers["Size"], 10, 64)
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("%w: invalid size", ErrInvalidBinaryPayload)
+	}
+	payload := &BinaryPayload{Name: name, Compression: Compression(headers["Compression"]), Armor: Armor(headers["Armor"])}
+	var compressed []byte
+	switch payload.Armor {
+	case ArmorBase64:
+		compressed, err = base64.StdEncoding.DecodeString(encoded)
+	case ArmorASCII85:
+		compressed, err = io.ReadAll(ascii85.NewDecoder(bytes.NewBufferString(encoded)))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedArmor, payload.Armor)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBinaryPayload, err.Error())
+	}
+	if payload.Compression == CompressionNone {
+		payload.Data = compressed
+	} else {
+		compressor, err := getCompressor(payload.Compression)
+		if err != nil {
+			return nil, err
+		}
+		reader, err := compressor.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBinaryPayload, err.Error())
+		}
+		defer reader.Close()
+		// Never decompress more than what the header announced, to protect against decompression bombs
+		payload.Data, err = io.ReadAll(io.LimitReader(reader, size+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBinaryPayload, err.Error())
+		}
+	}
+	if int64(len(payload.Data)) != size {
+		return nil, ErrBinaryPayloadSizeInvalid
+	}
+	checksum := sha256.Sum256(payload.Data)
+	if hex.EncodeToString(checksum[:]) != headers["SHA256"] {
+		return nil, ErrBinaryChecksumMismatch
+	}
+	return payload, nil
+}
+
+// CreateBinaryPaste encodes the data with EncodeBinaryPayload and creates a paste with it.
+// The Code of the request is replaced by the encoded payload.
+//
+// Since compressed and armored data cannot be scanned meaningfully, the data is passed through the scanner configured
+// with WithContentScanner before being encoded instead. Redacting would corrupt the data, so findings configured with
+// ScanActionRedact reject the paste, just like those configured with ScanActionDeny.
+func (c *Client) CreateBinaryPaste(request *CreatePasteRequest, name string, data []byte, compression Compression, armor Armor) (string, error) {
+	if err := c.scanBinaryContent(data); err != nil {
+		return "", err
+	}
+	encoded, err := EncodeBinaryPayload(name, data, compression, armor)
+	if err != nil {
+		return "", err
+	}
+	binaryRequest := *request
+	binaryRequest.Code = encoded
+	binaryRequest.Syntax = "text"
+	result, err := c.createPaste(&binaryRequest, false)
+	if err != nil {
+		return "", err
+	}
+	return result.Key, nil
+}
+
+// GetBinaryPaste retrieves a paste created with CreateBinaryPaste (or with the output of EncodeBinaryPayload)
+// and decodes it, verifying its checksum in the process.
+//
+// If the client is authenticated, the content is retrieved with GetUserPasteContent, which allows the retrieval of
+// private pastes. Otherwise, or if the paste does not belong to the user, GetPasteContent is used.
+func (c *Client) GetBinaryPaste(pasteKey string) (*BinaryPayload, error) {
+	content, err := c.fetchPasteContent(pasteKey)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBinaryPayload(content)
+}
@@ -0,0 +1,128 @@
+package pastebin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BulkResult is the result of a single item of a bulk operation
+type BulkResult[T any] struct {
+	// Value is the value returned for the item, which is the zero value if Err is not nil
+	Value T
+
+	// Err is the error returned for the item, if any
+	Err error
+}
+
+// BulkError is the error returned by bulk operations when at least one item failed.
+//
+// Every error is prefixed with the key of the item it was returned for, and can be matched with errors.Is and
+// errors.As, since BulkError implements Unwrap() []error.
+type BulkError struct {
+	// Total is the number of items in the bulk operation
+	Total int
+
+	// Errs are the errors of the items that failed
+	Errs []error
+}
+
+func (e *BulkError) Error() string {
+	messages := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("%d of %d operations failed: %s", len(e.Errs), e.Total, strings.Join(messages, "; "))
+}
+
+func (e *BulkError) Unwrap() []error {
+	return e.Errs
+}
+
+// runBulk calls fn for every distinct key with at most concurrency calls running at the same time, continuing past
+// individual failures. Returns the result of every key, and a *BulkError if any call returned an error.
+func runBulk[K comparable, T any](keys []K, concurrency int, fn func(key K) (T, error)) (map[K]BulkResult[T], error) {
+	distinctKeys := make([]K, 0, len(keys))
+	seen := make(map[K]bool, len(keys))
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			distinctKeys = append(distinctKeys, key)
+		}
+	}
+	results := make([]BulkResult[T], len(distinctKeys))
+	runConcurrently(len(distinctKeys), concurrency, func(i int) {
+		results[i].Value, results[i].Err = fn(distinctKeys[i])
+	})
+	resultByKey := make(map[K]BulkResult[T], len(distinctKeys))
+	var errs []error
+	for i, key := range distinctKeys {
+		resultByKey[key] = results[i]
+		if results[i].Err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", key, results[i].Err))
+		}
+	}
+	if len(errs) > 0 {
+		return resultByKey, &BulkError{Total: len(distinctKeys), Errs: errs}
+	}
+	return resultByKey, nil
+}
+
+// DeletePastes deletes multiple pastes owned by the authenticated user with at most concurrency requests in flight
+// at the same time. A concurrency of 0 or less defaults to DefaultConcurrency.
+//
+// Every paste is attempted even if some fail, and the rate limit configured with WithRateLimit is respected.
+// The error of every paste is returned, with nil for the pastes that were deleted, along with a *BulkError if any
+// paste could not be deleted.
+func (c *Client) DeletePastes(pasteKeys []string, concurrency int) (map[string]error, error) {
+	if len(c.getSessionKey()) == 0 {
+		return nil, ErrNotAuthenticated
+	}
+	var pastesByKey map[string]*Paste
+	if c.trash != nil {
+		// The trash needs the metadata of every paste, which is retrieved once rather than once per paste
+		pastes, _, _ := c.getAllUserPastes()
+		pastesByKey = make(map[string]*Paste, len(pastes))
+		for _, paste := range pastes {
+			pastesByKey[paste.Key] = paste
+		}
+	}
+	return c.deletePastes(pasteKeys, concurrency, pastesByKey)
+}
+
+// deletePastes deletes multiple pastes like DeletePastes, with the metadata of the pastes that the caller already has
+func (c *Client) deletePastes(pasteKeys []string, concurrency int, pastesByKey map[string]*Paste) (map[string]error, error) {
+	results, err := runBulk(pasteKeys, concurrency, func(pasteKey string) (struct{}, error) {
+		return struct{}{}, c.deletePaste(pasteKey, pastesByKey[pasteKey])
+	})
+	errByPasteKey := make(map[string]error, len(results))
+	for pasteKey, result := range results {
+		errByPasteKey[pasteKey] = result.Err
+	}
+	return errByPasteKey, err
+}
+
+// CreatePastes creates multiple pastes with at most concurrency requests in flight at the same time.
+// A concurrency of 0 or less defaults to DefaultConcurrency.
+//
+// Every request is attempted even if some fail, and the rate limit configured with WithRateLimit is respected.
+// The result of every request is returned, keyed by the index of the request in the requests parameter, along with
+// a *BulkError if any paste could not be created.
+func (c *Client) CreatePastes(requests []*CreatePasteRequest, concurrency int) (map[int]BulkResult[*CreatePasteResult], error) {
+	indexes := make([]int, len(requests))
+	for i := range requests {
+		indexes[i] = i
+	}
+	return runBulk(indexes, concurrency, func(i int) (*CreatePasteResult, error) {
+		return c.CreatePasteWithResult(requests[i])
+	})
+}
+
+// GetPasteContents retrieves the content of multiple pastes with at most concurrency requests in flight at the same
+// time. A concurrency of 0 or less defaults to DefaultConcurrency.
+//
+// Every paste is attempted even if some fail, and the rate limit configured with WithRateLimit is respected.
+// The result of every paste is returned, keyed by paste key, along with a *BulkError if the content of any paste
+// could not be retrieved.
+func (c *Client) GetPasteContents(pasteKeys []string, concurrency int) (map[string]BulkResult[string], error) {
+	return runBulk(pasteKeys, concurrency, c.GetPasteContent)
+}
@@ -0,0 +1,101 @@
+package pastebin
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func TestClient_DeletePastes(t *testing.T) {
+	var mutex sync.Mutex
+	var deleted []string
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		if request.URL.String() == LoginApiUrl {
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("session-key"))}
+		}
+		_ = request.ParseForm()
+		pasteKey := request.PostForm.Get("api_paste_key")
+		if pasteKey == "missing1" {
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("Bad API request, invalid permission to remove paste"))}
+		}
+		mutex.Lock()
+		deleted = append(deleted, pasteKey)
+		mutex.Unlock()
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("Paste Removed"))}
+	})}
+	client, _ := NewClient("username", "password", "token")
+	errs, err := client.DeletePastes([]string{"paste001", "missing1", "paste002", "paste003", "paste001"}, 2)
+	var bulkErr *BulkError
+	if !errors.As(err, &bulkErr) {
+		t.Fatalf("expected a *BulkError, got %v", err)
+	}
+	if bulkErr.Total != 4 || len(bulkErr.Errs) != 1 || !strings.HasPrefix(bulkErr.Errs[0].Error(), "missing1: ") {
+		t.Errorf("unexpected error %v", bulkErr)
+	}
+	if len(errs) != 4 || errs["missing1"] == nil || errs["paste001"] != nil || errs["paste002"] != nil || errs["paste003"] != nil {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	if len(deleted) != 3 {
+		t.Errorf("expected 3 pastes to be deleted once each, got %v", deleted)
+	}
+}
+
+func TestClient_DeletePastesWithoutCredentials(t *testing.T) {
+	client, _ := NewClient("", "", "token")
+	if _, err := client.DeletePastes([]string{"abcdefgh"}, 1); err != ErrNotAuthenticated {
+		t.Errorf("expected %v, got %v", ErrNotAuthenticated, err)
+	}
+}
+
+func TestClient_CreatePastes(t *testing.T) {
+	pastes := make(map[string]string)
+	httpClient = newMockPastebin(pastes)
+	client, _ := NewClient("", "", "token")
+	requests := []*CreatePasteRequest{
+		NewCreatePasteRequest("first", "content 1", ExpirationTenMinutes, VisibilityUnlisted, ""),
+		NewCreatePasteRequest("invalid", "", ExpirationTenMinutes, VisibilityUnlisted, ""),
+		NewCreatePasteRequest("third", "content 3", ExpirationTenMinutes, VisibilityUnlisted, ""),
+	}
+	// newMockPastebin is not safe for concurrent use, hence the concurrency of 1
+	results, err := client.CreatePastes(requests, 1)
+	if !errors.Is(err, ErrInvalidCreatePasteRequest) {
+		t.Errorf("expected %v, got %v", ErrInvalidCreatePasteRequest, err)
+	}
+	if len(results) != 3 || results[1].Err == nil || results[0].Err != nil || results[2].Err != nil {
+		t.Fatalf("unexpected results %v", results)
+	}
+	if pastes[results[0].Value.Key] != "content 1" || pastes[results[2].Value.Key] != "content 3" {
+		t.Errorf("unexpected pastes %v", pastes)
+	}
+}
+
+func TestClient_GetPasteContents(t *testing.T) {
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		pasteKey := strings.TrimPrefix(request.URL.String(), RawUrlPrefix+"/")
+		if pasteKey == "missing1" {
+			return &http.Response{StatusCode: 404, Body: io.NopCloser(bytes.NewBufferString("Not Found"))}
+		}
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("content of " + pasteKey))}
+	})}
+	client, _ := NewClient("", "", "token")
+	client.WithRateLimit(20 * time.Millisecond)
+	start := time.Now()
+	results, err := client.GetPasteContents([]string{"paste001", "paste002", "missing1"}, 3)
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("the rate limit should've been respected, took %s", elapsed)
+	}
+	var bulkErr *BulkError
+	if !errors.As(err, &bulkErr) || len(bulkErr.Errs) != 1 {
+		t.Errorf("expected a *BulkError with 1 error, got %v", err)
+	}
+	if results["paste001"].Value != "content of paste001" || results["paste002"].Value != "content of paste002" || results["missing1"].Err == nil {
+		t.Errorf("unexpected results %v", results)
+	}
+}
@@ -0,0 +1,348 @@
+package pastebin
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheEntry is the cached response of a content endpoint
+type CacheEntry struct {
+	Content []byte `json:"content"`
+
+	// ETag and LastModified are the validators returned by the endpoint, if any.
+	// They are used to revalidate the entry once it expires.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+
+	StoredAt  time.Time `json:"stored_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Cache is a store for the content of pastes
+//
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the entry associated with the key, even if the entry has expired
+	Get(key string) (*CacheEntry, bool)
+
+	// Set associates the entry with the key
+	Set(key string, entry *CacheEntry)
+
+	// Delete removes the entry associated with the key
+	Delete(key string)
+}
+
+// CacheStats are the statistics of the cache of a Client
+type CacheStats struct {
+	// Hits is the number of times the content was served from the cache without sending a request
+	Hits uint64
+
+	// Revalidations is the number of times an expired entry was served from the cache after the endpoint
+	// confirmed it had not been modified
+	Revalidations uint64
+
+	// Misses is the number of times the content had to be retrieved from the endpoint
+	Misses uint64
+}
+
+// HitRatio returns the ratio of requests served from the cache, including revalidated entries, to the total
+// number of requests. Returns 0 if there were no requests.
+func (s CacheStats) HitRatio() float64 {
+	total := s.Hits + s.Revalidations + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits+s.Revalidations) / float64(total)
+}
+
+// maxPasteExpirations is the maximum number of expiration dates remembered by the cache of a Client
+const maxPasteExpirations = 10000
+
+// contentCache is the cache configuration and state of a Client
+type contentCache struct {
+	cache Cache
+	ttl   time.Duration
+
+	// pasteExpirations are the expiration dates of the pastes whose metadata was retrieved by the client
+	pasteExpirations      map[string]time.Time
+	pasteExpirationsMutex sync.Mutex
+
+	hits          atomic.Uint64
+	revalidations atomic.Uint64
+	misses        atomic.Uint64
+}
+
+// WithCache configures the client to cache the content retrieved by GetPasteContent and
+// GetPasteContentUsingScrapingAPI for the given TTL.
+//
+// If the client retrieved the metadata of a paste (e.g. through GetPasteUsingScrapingAPI) and the paste expires
+// before the TTL, the entry expires at the same time as the paste. Expired entries that have an ETag or a
+// Last-Modified validator are revalidated with a conditional request rather than retrieved again.
+//
+// Passing a nil cache disables caching.
+//
+// Returns the client to allow chaining
+func (c *Client) WithCache(cache Cache, ttl time.Duration) *Client {
+	if cache == nil {
+		c.contentCache = nil
+	} else {
+		c.contentCache = &contentCache{cache: cache, ttl: ttl, pasteExpirations: make(map[string]time.Time)}
+	}
+	return c
+}
+
+// CacheStats returns the statistics of the cache configured with WithCache
+func (c *Client) CacheStats() CacheStats {
+	if c.contentCache == nil {
+		return CacheStats{}
+	}
+	return CacheStats{
+		Hits:          c.contentCache.hits.Load(),
+		Revalidations: c.contentCache.revalidations.Load(),
+		Misses:        c.contentCache.misses.Load(),
+	}
+}
+
+// rememberPasteExpirations records the expiration date of pastes, so that cached entries never outlive them
+func (c *Client) rememberPasteExpirations(pastes ...*Paste) {
+	if c.contentCache == nil {
+		return
+	}
+	now := time.Now()
+	c.contentCache.pasteExpirationsMutex.Lock()
+	defer c.contentCache.pasteExpirationsMutex.Unlock()
+	for _, paste := range pastes {
+		// Pastes that never expire have an expiration date of 0
+		if paste.ExpireDate.Unix() <= 0 {
+			continue
+		}
+		if _, exists := c.contentCache.pasteExpirations[paste.Key]; !exists && len(c.contentCache.pasteExpirations) >= maxPasteExpirations {
+			c.contentCache.evictPasteExpirations(now)
+		}
+		c.contentCache.pasteExpirations[paste.Key] = paste.ExpireDate
+	}
+}
+
+// evictPasteExpirations removes the expiration dates of the pastes that have expired, or the expiration date of the
+// paste expiring the soonest if none has expired. The caller must hold pasteExpirationsMutex.
+func (cc *contentCache) evictPasteExpirations(now time.Time) {
+	var soonestPasteKey string
+	var soonestExpireDate time.Time
+	for pasteKey, expireDate := range cc.pasteExpirations {
+		if expireDate.Before(now) {
+			delete(cc.pasteExpirations, pasteKey)
+		} else if len(soonestPasteKey) == 0 || expireDate.Before(soonestExpireDate) {
+			soonestPasteKey, soonestExpireDate = pasteKey, expireDate
+		}
+	}
+	if len(cc.pasteExpirations) >= maxPasteExpirations {
+		delete(cc.pasteExpirations, soonestPasteKey)
+	}
+}
+
+// pasteExpiration returns the expiration date of the paste, if the client retrieved its metadata before
+func (cc *contentCache) pasteExpiration(pasteKey string) (time.Time, bool) {
+	cc.pasteExpirationsMutex.Lock()
+	defer cc.pasteExpirationsMutex.Unlock()
+	expireDate, known := cc.pasteExpirations[pasteKey]
+	return expireDate, known
+}
+
+// forgetPaste removes everything the cache knows about a paste, which is called once the paste is deleted
+func (c *Client) forgetPaste(pasteKey string) {
+	if c.contentCache == nil {
+		return
+	}
+	c.contentCache.cache.Delete(string(OperationGetPasteContent) + ":" + pasteKey)
+	c.contentCache.cache.Delete(string(OperationGetPasteContentUsingScrapingAPI) + ":" + pasteKey)
+	c.contentCache.pasteExpirationsMutex.Lock()
+	delete(c.contentCache.pasteExpirations, pasteKey)
+	c.contentCache.pasteExpirationsMutex.Unlock()
+}
+
+// getCachedContent retrieves the content of a paste from the given URL, using the cache configured on the client
+func (c *Client) getCachedContent(operation Operation, pasteKey, requestUrl string) ([]byte, error) {
+	if c.contentCache == nil {
+		return c.doPublicRequest(operation, pasteKey, "GET", requestUrl)
+	}
+	cacheKey := string(operation) + ":" + pasteKey
+	now := time.Now()
+	pasteExpireDate, _ := c.contentCache.pasteExpiration(pasteKey)
+	entry, exists := c.contentCache.cache.Get(cacheKey)
+	if exists && !pasteExpireDate.IsZero() && now.After(pasteExpireDate) {
+		// The paste itself has expired, so there's no point in keeping or revalidating the entry
+		c.contentCache.cache.Delete(cacheKey)
+		exists = false
+	}
+	if exists && now.Before(entry.ExpiresAt) {
+		c.contentCache.hits.Add(1)
+		return entry.Content, nil
+	}
+	request, err := http.NewRequest("GET", requestUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if len(entry.ETag) > 0 {
+			request.Header.Set("If-None-Match", entry.ETag)
+		}
+		if len(entry.LastModified) > 0 {
+			request.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+	response, body, err := c.doRequest(operation, pasteKey, request)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := now.Add(c.contentCache.ttl)
+	if !pasteExpireDate.IsZero() && pasteExpireDate.Before(expiresAt) {
+		expiresAt = pasteExpireDate
+	}
+	if exists && response.StatusCode == http.StatusNotModified {
+		c.contentCache.revalidations.Add(1)
+		revalidatedEntry := *entry
+		revalidatedEntry.StoredAt = now
+		revalidatedEntry.ExpiresAt = expiresAt
+		c.contentCache.cache.Set(cacheKey, &revalidatedEntry)
+		return entry.Content, nil
+	}
+	c.contentCache.misses.Add(1)
+	if err = checkPublicResponse(response, body); err != nil {
+		return nil, err
+	}
+	c.contentCache.cache.Set(cacheKey, &CacheEntry{
+		Content:      body,
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+		StoredAt:     now,
+		ExpiresAt:    expiresAt,
+	})
+	return body, nil
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used entry once it reaches its maximum size
+type MemoryCache struct {
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+	mutex      sync.Mutex
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryCache creates a new MemoryCache that holds up to maxEntries entries.
+// If maxEntries is 0 or less, the cache is unbounded.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Get returns the entry associated with the key and marks it as recently used
+func (mc *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	element, exists := mc.entries[key]
+	if !exists {
+		return nil, false
+	}
+	mc.lru.MoveToFront(element)
+	return element.Value.(*memoryCacheItem).entry, true
+}
+
+// Set associates the entry with the key, evicting the least recently used entry if the cache is full
+func (mc *MemoryCache) Set(key string, entry *CacheEntry) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	if element, exists := mc.entries[key]; exists {
+		element.Value.(*memoryCacheItem).entry = entry
+		mc.lru.MoveToFront(element)
+		return
+	}
+	mc.entries[key] = mc.lru.PushFront(&memoryCacheItem{key: key, entry: entry})
+	if mc.maxEntries > 0 && mc.lru.Len() > mc.maxEntries {
+		oldest := mc.lru.Back()
+		mc.lru.Remove(oldest)
+		delete(mc.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+// Delete removes the entry associated with the key
+func (mc *MemoryCache) Delete(key string) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	if element, exists := mc.entries[key]; exists {
+		mc.lru.Remove(element)
+		delete(mc.entries, key)
+	}
+}
+
+// Len returns the number of entries in the cache
+func (mc *MemoryCache) Len() int {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	return mc.lru.Len()
+}
+
+// DiskCache is a Cache that stores each entry as a JSON file in a directory, which allows the cache to survive
+// restarts and to be shared between processes
+type DiskCache struct {
+	directory string
+}
+
+// NewDiskCache creates a new DiskCache that stores its entries in the given directory, creating it if necessary
+func NewDiskCache(directory string) (*DiskCache, error) {
+	if err := os.MkdirAll(directory, 0o700); err != nil {
+		return nil, err
+	}
+	return &DiskCache{directory: directory}, nil
+}
+
+// Get returns the entry associated with the key
+func (dc *DiskCache) Get(key string) (*CacheEntry, bool) {
+	data, err := os.ReadFile(dc.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry CacheEntry
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Set associates the entry with the key
+//
+// The entry is written to a temporary file which is then renamed, so concurrent readers never see a partial entry.
+func (dc *DiskCache) Set(key string, entry *CacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	_ = writeFileAtomically(dc.path(key), data)
+}
+
+// Delete removes the entry associated with the key
+func (dc *DiskCache) Delete(key string) {
+	_ = os.Remove(dc.path(key))
+}
+
+// path returns the path of the file for the key, which is hashed because cache keys may contain characters
+// that are not allowed in file names
+func (dc *DiskCache) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(dc.directory, hex.EncodeToString(hash[:])+".json")
+}
@@ -0,0 +1,150 @@
+package pastebin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func TestClient_WithCache(t *testing.T) {
+	numberOfRequests := 0
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		numberOfRequests++
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			return &http.Response{StatusCode: 304, Body: io.NopCloser(bytes.NewBufferString(""))}
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Etag": {`"v1"`}},
+			Body:       io.NopCloser(bytes.NewBufferString("this is code")),
+		}
+	})}
+	client, _ := NewClient("", "", "token")
+	client.WithCache(NewMemoryCache(10), time.Hour)
+	for i := 0; i < 3; i++ {
+		if content, err := client.GetPasteContent("abcdefgh"); err != nil || content != "this is code" {
+			t.Fatalf("expected '%s', got '%s' with error %v", "this is code", content, err)
+		}
+	}
+	if numberOfRequests != 1 {
+		t.Errorf("expected %d request, got %d", 1, numberOfRequests)
+	}
+	// Force the entry to expire, which should trigger a conditional request
+	entry, _ := client.contentCache.cache.Get(string(OperationGetPasteContent) + ":abcdefgh")
+	entry.ExpiresAt = time.Now().Add(-time.Second)
+	if content, err := client.GetPasteContent("abcdefgh"); err != nil || content != "this is code" {
+		t.Fatalf("expected '%s', got '%s' with error %v", "this is code", content, err)
+	}
+	stats := client.CacheStats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Revalidations != 1 {
+		t.Errorf("expected 2 hits, 1 miss and 1 revalidation, got %+v", stats)
+	}
+	if stats.HitRatio() != 0.75 {
+		t.Errorf("expected hit ratio to be %f, got %f", 0.75, stats.HitRatio())
+	}
+}
+
+func TestClient_WithCacheWhenPasteExpires(t *testing.T) {
+	numberOfRequests := 0
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		numberOfRequests++
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("this is code"))}
+	})}
+	client, _ := NewClient("", "", "token")
+	client.WithCache(NewMemoryCache(10), time.Hour)
+	client.rememberPasteExpirations(&Paste{Key: "abcdefgh", ExpireDate: time.Now().Add(-time.Minute)})
+	_, _ = client.GetPasteContent("abcdefgh")
+	_, _ = client.GetPasteContent("abcdefgh")
+	if numberOfRequests != 2 {
+		t.Errorf("the cache should not have been used for an expired paste, expected %d requests, got %d", 2, numberOfRequests)
+	}
+}
+
+func TestClient_WithCacheWhenPasteDeleted(t *testing.T) {
+	account := newMockAccount()
+	account.pastes["abcdefgh"] = &mockAccountPaste{title: "title", content: "content"}
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	client.WithCache(NewMemoryCache(10), time.Hour)
+	for _, operation := range []Operation{OperationGetPasteContent, OperationGetPasteContentUsingScrapingAPI} {
+		client.contentCache.cache.Set(string(operation)+":abcdefgh", &CacheEntry{Content: []byte("content"), StoredAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)})
+	}
+	client.rememberPasteExpirations(&Paste{Key: "abcdefgh", ExpireDate: time.Now().Add(time.Hour)})
+	if err := client.DeletePaste("abcdefgh"); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	for _, operation := range []Operation{OperationGetPasteContent, OperationGetPasteContentUsingScrapingAPI} {
+		if _, exists := client.contentCache.cache.Get(string(operation) + ":abcdefgh"); exists {
+			t.Errorf("the cached content of a deleted paste should've been removed for %s", operation)
+		}
+	}
+	if _, known := client.contentCache.pasteExpiration("abcdefgh"); known {
+		t.Error("the expiration date of a deleted paste should've been forgotten")
+	}
+}
+
+func TestClient_rememberPasteExpirationsIsBounded(t *testing.T) {
+	client, _ := NewClient("", "", "token")
+	client.WithCache(NewMemoryCache(10), time.Hour)
+	now := time.Now()
+	client.rememberPasteExpirations(&Paste{Key: "expired", ExpireDate: now.Add(-time.Minute)})
+	for i := 1; i < maxPasteExpirations; i++ {
+		client.rememberPasteExpirations(&Paste{Key: fmt.Sprintf("paste%05d", i), ExpireDate: now.Add(time.Duration(i) * time.Hour)})
+	}
+	client.rememberPasteExpirations(&Paste{Key: "newest", ExpireDate: now.AddDate(1, 0, 0)})
+	if _, known := client.contentCache.pasteExpiration("expired"); known {
+		t.Error("the expired paste should've been evicted first")
+	}
+	client.rememberPasteExpirations(&Paste{Key: "latest", ExpireDate: now.AddDate(1, 0, 0)})
+	if _, known := client.contentCache.pasteExpiration("paste00001"); known {
+		t.Error("the paste expiring the soonest should've been evicted once no paste had expired")
+	}
+	if len(client.contentCache.pasteExpirations) != maxPasteExpirations {
+		t.Errorf("expected %d expiration dates, got %d", maxPasteExpirations, len(client.contentCache.pasteExpirations))
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", &CacheEntry{Content: []byte("a")})
+	cache.Set("b", &CacheEntry{Content: []byte("b")})
+	cache.Get("a") // "b" is now the least recently used entry
+	cache.Set("c", &CacheEntry{Content: []byte("c")})
+	if _, exists := cache.Get("b"); exists {
+		t.Error("entry b should've been evicted")
+	}
+	if _, exists := cache.Get("a"); !exists {
+		t.Error("entry a should not have been evicted")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("expected %d entries, got %d", 2, cache.Len())
+	}
+	cache.Delete("a")
+	if _, exists := cache.Get("a"); exists {
+		t.Error("entry a should've been deleted")
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	cache.Set("get_paste_content:abcdefgh", &CacheEntry{Content: []byte("content"), ETag: `"v1"`})
+	entry, exists := cache.Get("get_paste_content:abcdefgh")
+	if !exists {
+		t.Fatal("entry should exist")
+	}
+	if string(entry.Content) != "content" || entry.ETag != `"v1"` {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	cache.Delete("get_paste_content:abcdefgh")
+	if _, exists = cache.Get("get_paste_content:abcdefgh"); exists {
+		t.Error("entry should've been deleted")
+	}
+}
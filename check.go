@@ -0,0 +1,155 @@
+package pastebin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// PasteStatus is the status of a paste, as returned by CheckPaste
+type PasteStatus int
+
+const (
+	// PasteStatusUnknown is returned alongside an error when the status of the paste could not be determined
+	PasteStatusUnknown PasteStatus = iota
+
+	// PasteStatusExists means the paste exists and can be retrieved without authentication
+	PasteStatusExists
+
+	// PasteStatusPrivate means the paste exists, but can only be retrieved by its owner
+	PasteStatusPrivate
+
+	// PasteStatusExpired means the paste no longer exists because it expired
+	PasteStatusExpired
+
+	// PasteStatusRemoved means the paste was removed by Pastebin, usually for violating its terms of service
+	PasteStatusRemoved
+
+	// PasteStatusNotFound means the paste does not exist, either because it never existed or because it was deleted
+	PasteStatusNotFound
+)
+
+func (s PasteStatus) String() string {
+	switch s {
+	case PasteStatusExists:
+		return "exists"
+	case PasteStatusPrivate:
+		return "private"
+	case PasteStatusExpired:
+		return "expired"
+	case PasteStatusRemoved:
+		return "removed"
+	case PasteStatusNotFound:
+		return "not_found"
+	default:
+		return "unknown"
+	}
+}
+
+// IsAccessible returns whether the content of the paste can be retrieved, possibly by its owner only
+func (s PasteStatus) IsAccessible() bool {
+	return s == PasteStatusExists || s == PasteStatusPrivate
+}
+
+// CheckPaste returns the status of a paste without downloading its content.
+// See Client.CheckPaste for more information.
+func CheckPaste(pasteKey string) (PasteStatus, error) {
+	return (&Client{}).CheckPaste(pasteKey)
+}
+
+// CheckPaste returns the status of a paste without downloading its content.
+//
+// The status is derived from the status code of a HEAD request to the raw endpoint. If the paste could not be found,
+// the metadata endpoint of the scraping API is used to tell whether it expired or was removed, which requires the IP
+// to be whitelisted. Otherwise, PasteStatusExpired is only returned if the client already knew when the paste would
+// expire (see WithCache), and PasteStatusNotFound is returned in every other case.
+//
+// Note that a paste owned by the client is reported as PasteStatusPrivate if it's private, even though the client can
+// retrieve its content with GetUserPasteContent.
+func (c *Client) CheckPaste(pasteKey string) (PasteStatus, error) {
+	if err := ValidatePasteKey(pasteKey); err != nil {
+		return PasteStatusUnknown, err
+	}
+	request, err := http.NewRequest("HEAD", RawUrlPrefix+"/"+pasteKey, nil)
+	if err != nil {
+		return PasteStatusUnknown, err
+	}
+	response, _, err := c.doRequest(OperationCheckPaste, pasteKey, request)
+	if err != nil {
+		return PasteStatusUnknown, err
+	}
+	switch response.StatusCode {
+	case http.StatusOK:
+		return PasteStatusExists, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return PasteStatusPrivate, nil
+	case http.StatusNotFound, http.StatusGone:
+		return c.checkMissingPaste(pasteKey), nil
+	default:
+		return PasteStatusUnknown, fmt.Errorf("unexpected status code %d while checking paste %s", response.StatusCode, pasteKey)
+	}
+}
+
+// checkMissingPaste determines why a paste that could not be found through the raw endpoint is missing
+func (c *Client) checkMissingPaste(pasteKey string) PasteStatus {
+	if expireDate, known := c.knownPasteExpiration(pasteKey); known && expireDate.Before(time.Now()) {
+		return PasteStatusExpired
+	}
+	request, err := http.NewRequest("GET", ScrapeItemMetadataApiUrl+"?"+url.Values{"i": {pasteKey}}.Encode(), nil)
+	if err != nil {
+		return PasteStatusNotFound
+	}
+	_, body, err := c.doRequest(OperationCheckPaste, pasteKey, request)
+	if err != nil {
+		return PasteStatusNotFound
+	}
+	return pasteStatusFromScrapingResponse(body)
+}
+
+// pasteStatusFromScrapingResponse returns the status of a paste that could not be found through the raw endpoint
+// based on the response of the metadata endpoint of the scraping API
+func pasteStatusFromScrapingResponse(body []byte) PasteStatus {
+	var jsonPaste jsonPaste
+	if err := json.Unmarshal(body, &jsonPaste); err == nil {
+		if paste := jsonPaste.ToPaste(); paste.ExpireDate.Unix() > 0 && paste.ExpireDate.Before(time.Now()) {
+			return PasteStatusExpired
+		}
+		// The scraping API only has access to public pastes, so the raw endpoint was most likely lagging behind
+		return PasteStatusExists
+	}
+	message := strings.ToLower(string(body))
+	switch {
+	case strings.Contains(message, "removed") || strings.Contains(message, "violat") || strings.Contains(message, "terms of service"):
+		return PasteStatusRemoved
+	case strings.Contains(message, "expired"):
+		return PasteStatusExpired
+	default:
+		// Includes "Error, we cannot find this paste." and the error returned when the IP is not whitelisted
+		return PasteStatusNotFound
+	}
+}
+
+// knownPasteExpiration returns the expiration date of the paste, if the client retrieved its metadata before
+func (c *Client) knownPasteExpiration(pasteKey string) (time.Time, bool) {
+	if c.contentCache == nil {
+		return time.Time{}, false
+	}
+	return c.contentCache.pasteExpiration(pasteKey)
+}
+
+// CheckPastes checks the status of multiple pastes with at most concurrency requests in flight at the same time.
+// A concurrency of 0 or less defaults to DefaultConcurrency.
+//
+// The status of every paste is returned, with PasteStatusUnknown for the pastes that could not be checked, along with
+// a *BulkError if any paste could not be checked.
+func (c *Client) CheckPastes(pasteKeys []string, concurrency int) (map[string]PasteStatus, error) {
+	results, err := runBulk(pasteKeys, concurrency, c.CheckPaste)
+	statusByPasteKey := make(map[string]PasteStatus, len(results))
+	for pasteKey, result := range results {
+		statusByPasteKey[pasteKey] = result.Value
+	}
+	return statusByPasteKey, err
+}
@@ -0,0 +1,111 @@
+package pastebin
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func TestCheckPaste(t *testing.T) {
+	expiredAt := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	testCases := []struct {
+		desc             string
+		rawStatusCode    int
+		scrapingResponse string
+		expectedStatus   PasteStatus
+	}{
+		{desc: "exists", rawStatusCode: 200, expectedStatus: PasteStatusExists},
+		{desc: "private", rawStatusCode: 403, expectedStatus: PasteStatusPrivate},
+		{desc: "not-found", rawStatusCode: 404, scrapingResponse: "Error, we cannot find this paste.", expectedStatus: PasteStatusNotFound},
+		{desc: "not-found-without-scraping-access", rawStatusCode: 404, scrapingResponse: "YOUR IP: 127.0.0.1 DOES NOT HAVE ACCESS.", expectedStatus: PasteStatusNotFound},
+		{desc: "removed", rawStatusCode: 404, scrapingResponse: "Error, this paste has been removed for violating our terms of service.", expectedStatus: PasteStatusRemoved},
+		{desc: "expired", rawStatusCode: 404, scrapingResponse: `{"key":"abcdefgh","expire":"` + expiredAt + `"}`, expectedStatus: PasteStatusExpired},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+				if strings.HasPrefix(request.URL.String(), ScrapeItemMetadataApiUrl) {
+					return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString(tC.scrapingResponse))}
+				}
+				if request.Method != "HEAD" {
+					t.Errorf("expected a HEAD request to the raw endpoint, got %s", request.Method)
+				}
+				return &http.Response{StatusCode: tC.rawStatusCode, Body: io.NopCloser(bytes.NewBufferString(""))}
+			})}
+			status, err := CheckPaste("abcdefgh")
+			if err != nil {
+				t.Fatal("shouldn't have returned an error, got", err)
+			}
+			if status != tC.expectedStatus {
+				t.Errorf("expected %s, got %s", tC.expectedStatus, status)
+			}
+		})
+	}
+}
+
+func TestClient_CheckPasteWithKnownExpiration(t *testing.T) {
+	var scrapingRequests int
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		if strings.HasPrefix(request.URL.String(), ScrapeItemMetadataApiUrl) {
+			scrapingRequests++
+		}
+		return &http.Response{StatusCode: 404, Body: io.NopCloser(bytes.NewBufferString(""))}
+	})}
+	client, _ := NewClient("", "", "token")
+	client.WithCache(NewMemoryCache(10), time.Hour)
+	client.rememberPasteExpirations(&Paste{Key: "abcdefgh", ExpireDate: time.Now().Add(-time.Minute)})
+	status, err := client.CheckPaste("abcdefgh")
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if status != PasteStatusExpired {
+		t.Errorf("expected %s, got %s", PasteStatusExpired, status)
+	}
+	if scrapingRequests != 0 {
+		t.Error("the scraping API should not have been used for a paste whose expiration was known")
+	}
+}
+
+func TestClient_CheckPasteWithUnexpectedResponse(t *testing.T) {
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		return &http.Response{StatusCode: 503, Body: io.NopCloser(bytes.NewBufferString(""))}
+	})}
+	client, _ := NewClient("", "", "token")
+	if status, err := client.CheckPaste("abcdefgh"); err == nil || status != PasteStatusUnknown {
+		t.Errorf("expected %s and an error, got %s and %v", PasteStatusUnknown, status, err)
+	}
+	if _, err := client.CheckPaste("not a key"); !errors.Is(err, ErrInvalidPasteKey) {
+		t.Errorf("expected %v, got %v", ErrInvalidPasteKey, err)
+	}
+}
+
+func TestClient_CheckPastes(t *testing.T) {
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		switch strings.TrimPrefix(request.URL.String(), RawUrlPrefix+"/") {
+		case "public01":
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString(""))}
+		case "private1":
+			return &http.Response{StatusCode: 403, Body: io.NopCloser(bytes.NewBufferString(""))}
+		default:
+			return &http.Response{StatusCode: 500, Body: io.NopCloser(bytes.NewBufferString(""))}
+		}
+	})}
+	client, _ := NewClient("", "", "token")
+	statuses, err := client.CheckPastes([]string{"public01", "private1", "broken01"}, 2)
+	if err == nil || !strings.Contains(err.Error(), "broken01") {
+		t.Errorf("expected an error for broken01, got %v", err)
+	}
+	expectedStatuses := map[string]PasteStatus{"public01": PasteStatusExists, "private1": PasteStatusPrivate, "broken01": PasteStatusUnknown}
+	for pasteKey, expectedStatus := range expectedStatuses {
+		if statuses[pasteKey] != expectedStatus {
+			t.Errorf("expected %s for %s, got %s", expectedStatus, pasteKey, statuses[pasteKey])
+		}
+	}
+}
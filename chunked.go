@@ -0,0 +1,333 @@
+package pastebin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	chunkedManifestFormat  = "go-pastebin/chunked-manifest"
+	chunkedManifestVersion = 1
+)
+
+// DefaultChunkSize is the maximum size of a paste for free Pastebin accounts
+const DefaultChunkSize = 512 * 1024
+
+// maxChunkedPasteSize is the maximum total size of a chunked paste retrieved by GetChunkedPasteContent, since the size
+// is read from the manifest, which anyone can create
+const maxChunkedPasteSize = 1024 * 1024 * 1024
+
+var (
+	ErrInvalidChunkedManifest = errors.New("paste is not a valid chunked paste manifest")
+	ErrChunkChecksumMismatch  = errors.New("checksum of a chunk does not match the checksum in the manifest")
+	ErrChunkedPasteClosed     = errors.New("chunked paste writer is already closed")
+	ErrEmptyChunkedPaste      = errors.New("cannot create a chunked paste without content")
+)
+
+// ChunkedManifest is the content of the paste that lists the chunks of a chunked paste
+type ChunkedManifest struct {
+	Format    string          `json:"format"`
+	Version   int             `json:"version"`
+	TotalSize int             `json:"total_size"`
+	SHA256    string          `json:"sha256"`
+	Chunks    []ManifestChunk `json:"chunks"`
+}
+
+// ManifestChunk is a single chunk of a chunked paste
+type ManifestChunk struct {
+	Index  int    `json:"index"`
+	Key    string `json:"key"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ChunkedUploadError is returned when a chunked upload fails after some chunks were already created
+type ChunkedUploadError struct {
+	Err error
+
+	// OrphanedKeys are the keys of the chunks that were created, but could not be deleted during the cleanup.
+	// Note that guest pastes cannot be deleted, so this will contain every chunk created by a guest client.
+	OrphanedKeys []string
+}
+
+func (e *ChunkedUploadError) Error() string {
+	if len(e.OrphanedKeys) == 0 {
+		return fmt.Sprintf("chunked upload failed: %s", e.Err.Error())
+	}
+	return fmt.Sprintf("chunked upload failed: %s (orphaned chunks: %s)", e.Err.Error(), strings.Join(e.OrphanedKeys, ", "))
+}
+
+func (e *ChunkedUploadError) Unwrap() error {
+	return e.Err
+}
+
+// ChunkedPasteWriter splits everything written to it across multiple pastes of at most chunkSize bytes.
+// Closing the writer creates the manifest paste, whose key can be retrieved with ManifestKey.
+//
+// Since the content is streamed, each chunk is passed through the scanner configured with WithContentScanner
+// separately, which means that a secret spanning two chunks may not be detected. Use CreateChunkedPaste to scan the
+// content as a whole.
+//
+// If creating a chunk or the manifest fails, the chunks that were already created are deleted with DeletePaste
+// and a *ChunkedUploadError is returned.
+type ChunkedPasteWriter struct {
+	client    *Client
+	request   CreatePasteRequest
+	chunkSize int
+
+	// scan is whether the chunks still have to be passed through the scanner
+	scan bool
+
+	// buffer is the content that was written but not scanned yet, and pending is the content that was scanned but
+	// not uploaded yet
+	buffer      []byte
+	pending     []byte
+	manifest    ChunkedManifest
+	totalHasher hash.Hash
+	manifestKey string
+	closed      bool
+	err         error
+}
+
+// NewChunkedPasteWriter creates a new ChunkedPasteWriter
+//
+// The title, expiration, visibility and syntax of the request are used for every chunk and for the manifest.
+// The Code of the request is ignored. If chunkSize is 0 or less, DefaultChunkSize is used.
+func (c *Client) NewChunkedPasteWriter(request *CreatePasteRequest, chunkSize int) *ChunkedPasteWriter {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	return &ChunkedPasteWriter{
+		client:      c,
+		request:     *request,
+		chunkSize:   chunkSize,
+		scan:        true,
+		manifest:    ChunkedManifest{Format: chunkedManifestFormat, Version: chunkedManifestVersion},
+		totalHasher: sha256.New(),
+	}
+}
+
+// Write buffers p, creating a chunk paste every time the buffer exceeds the chunk size
+func (w *ChunkedPasteWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrChunkedPasteClosed
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.buffer = append(w.buffer, p...)
+	for len(w.buffer) > w.chunkSize {
+		if err := w.scanBuffer(chunkBoundary(w.buffer, w.chunkSize)); err != nil {
+			return 0, err
+		}
+		for len(w.pending) > w.chunkSize {
+			if err := w.flush(chunkBoundary(w.pending, w.chunkSize)); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(p), nil
+}
+
+// Close creates the last chunks as well as the manifest paste.
+// Returns ErrEmptyChunkedPaste if nothing was written.
+func (w *ChunkedPasteWriter) Close() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.scanBuffer(len(w.buffer)); err != nil {
+		return err
+	}
+	if len(w.pending) == 0 && len(w.manifest.Chunks) == 0 {
+		w.err = ErrEmptyChunkedPaste
+		return w.err
+	}
+	for len(w.pending) > 0 {
+		n := len(w.pending)
+		if n > w.chunkSize {
+			n = chunkBoundary(w.pending, w.chunkSize)
+		}
+		if err := w.flush(n); err != nil {
+			return err
+		}
+	}
+	w.manifest.SHA256 = hex.EncodeToString(w.totalHasher.Sum(nil))
+	manifestJSON, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return w.fail(err)
+	}
+	manifestRequest := w.request
+	manifestRequest.Code = string(manifestJSON)
+	manifestRequest.Syntax = "json"
+	w.manifestKey, err = w.client.CreatePaste(&manifestRequest)
+	if err != nil {
+		return w.fail(err)
+	}
+	return nil
+}
+
+// ManifestKey returns the key of the manifest paste, which is only available once the writer has been closed
+func (w *ChunkedPasteWriter) ManifestKey() string {
+	return w.manifestKey
+}
+
+// scanBuffer passes the first n bytes of the buffer through the scanner and moves them to the pending content.
+//
+// The content is scanned here rather than by createPaste, so that the checksums match the uploaded content and so that
+// the chunks can be split again if redacting secrets made the content larger.
+func (w *ChunkedPasteWriter) scanBuffer(n int) error {
+	scanned := string(w.buffer[:n])
+	if w.scan {
+		var err error
+		if scanned, err = w.client.scanContent(scanned); err != nil {
+			return w.fail(err)
+		}
+	}
+	w.pending = append(w.pending, scanned...)
+	w.buffer = w.buffer[n:]
+	return nil
+}
+
+// flush creates a chunk paste with the first n bytes of the pending content
+func (w *ChunkedPasteWriter) flush(n int) error {
+	chunk := w.pending[:n]
+	chunkRequest := w.request
+	chunkRequest.Code = string(chunk)
+	chunkRequest.Title = fmt.Sprintf("%s (part %d)", w.request.Title, len(w.manifest.Chunks)+1)
+	result, err := w.client.createPaste(&chunkRequest, false)
+	if err != nil {
+		return w.fail(err)
+	}
+	checksum := sha256.Sum256(chunk)
+	w.totalHasher.Write(chunk)
+	w.manifest.Chunks = append(w.manifest.Chunks, ManifestChunk{
+		Index:  len(w.manifest.Chunks),
+		Key:    result.Key,
+		Size:   len(chunk),
+		SHA256: hex.EncodeToString(checksum[:]),
+	})
+	w.manifest.TotalSize += len(chunk)
+	w.pending = w.pending[n:]
+	return nil
+}
+
+// fail deletes every chunk created so far and sets the error of the writer
+func (w *ChunkedPasteWriter) fail(err error) error {
+	uploadErr := &ChunkedUploadError{Err: err}
+	for _, chunk := range w.manifest.Chunks {
+		if deleteErr := w.client.DeletePaste(chunk.Key); deleteErr != nil {
+			uploadErr.OrphanedKeys = append(uploadErr.OrphanedKeys, chunk.Key)
+		}
+	}
+	w.manifest.Chunks = nil
+	w.err = uploadErr
+	return uploadErr
+}
+
+// chunkBoundary returns the largest index smaller or equal to maxSize that does not split a UTF-8 character
+func chunkBoundary(data []byte, maxSize int) int {
+	for i := maxSize; i > maxSize-utf8.UTFMax && i > 0; i-- {
+		if utf8.RuneStart(data[i]) {
+			return i
+		}
+	}
+	return maxSize
+}
+
+// CreateChunkedPaste creates a chunked paste with the code of the request split into chunks of at most chunkSize
+// bytes, and returns the key of the manifest paste.
+//
+// Unlike with NewChunkedPasteWriter, the content is passed through the scanner configured with WithContentScanner as
+// a whole before being split, so secrets spanning two chunks are detected as well.
+//
+// If chunkSize is 0 or less, DefaultChunkSize is used.
+// See NewChunkedPasteWriter
+func (c *Client) CreateChunkedPaste(request *CreatePasteRequest, chunkSize int) (string, error) {
+	if len(request.Code) == 0 {
+		return "", ErrEmptyChunkedPaste
+	}
+	code, err := c.scanContent(request.Code)
+	if err != nil {
+		return "", err
+	}
+	writer := c.NewChunkedPasteWriter(request, chunkSize)
+	writer.scan = false
+	if _, err = writer.Write([]byte(code)); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return writer.ManifestKey(), nil
+}
+
+// GetChunkedPasteContent retrieves the manifest of a chunked paste as well as each of its chunks, verifies
+// their checksums and returns the reassembled content.
+//
+// Manifests whose sizes are inconsistent, or whose total size exceeds 1 GiB, are rejected with
+// ErrInvalidChunkedManifest before any chunk is retrieved.
+func (c *Client) GetChunkedPasteContent(manifestKey string) (string, error) {
+	manifestContent, err := c.fetchPasteContent(manifestKey)
+	if err != nil {
+		return "", err
+	}
+	var manifest ChunkedManifest
+	if err = json.Unmarshal([]byte(manifestContent), &manifest); err != nil || manifest.Format != chunkedManifestFormat {
+		return "", ErrInvalidChunkedManifest
+	}
+	if manifest.Version != chunkedManifestVersion {
+		return "", fmt.Errorf("%w: unsupported version %d", ErrInvalidChunkedManifest, manifest.Version)
+	}
+	if err = manifest.validateSizes(); err != nil {
+		return "", err
+	}
+	var content strings.Builder
+	content.Grow(manifest.TotalSize)
+	for i, chunk := range manifest.Chunks {
+		if chunk.Index != i {
+			return "", fmt.Errorf("%w: chunk %d is out of order", ErrInvalidChunkedManifest, chunk.Index)
+		}
+		chunkContent, err := c.fetchPasteContent(chunk.Key)
+		if err != nil {
+			return "", fmt.Errorf("failed to retrieve chunk %d (%s): %w", chunk.Index, chunk.Key, err)
+		}
+		checksum := sha256.Sum256([]byte(chunkContent))
+		if len(chunkContent) != chunk.Size || hex.EncodeToString(checksum[:]) != chunk.SHA256 {
+			return "", fmt.Errorf("%w: chunk %d (%s)", ErrChunkChecksumMismatch, chunk.Index, chunk.Key)
+		}
+		content.WriteString(chunkContent)
+	}
+	checksum := sha256.Sum256([]byte(content.String()))
+	if content.Len() != manifest.TotalSize || hex.EncodeToString(checksum[:]) != manifest.SHA256 {
+		return "", ErrChunkChecksumMismatch
+	}
+	return content.String(), nil
+}
+
+// validateSizes returns an error if the sizes of the manifest are negative, too large or inconsistent with one another
+func (m *ChunkedManifest) validateSizes() error {
+	if m.TotalSize < 0 || m.TotalSize > maxChunkedPasteSize {
+		return fmt.Errorf("%w: invalid total size %d", ErrInvalidChunkedManifest, m.TotalSize)
+	}
+	totalSize := 0
+	for _, chunk := range m.Chunks {
+		if chunk.Size < 0 || chunk.Size > m.TotalSize-totalSize {
+			return fmt.Errorf("%w: invalid size %d for chunk %d", ErrInvalidChunkedManifest, chunk.Size, chunk.Index)
+		}
+		totalSize += chunk.Size
+	}
+	if totalSize != m.TotalSize {
+		return fmt.Errorf("%w: total size %d does not match the size of the chunks %d", ErrInvalidChunkedManifest, m.TotalSize, totalSize)
+	}
+	return nil
+}
@@ -0,0 +1,533 @@
+// Command pastebin is a command-line interface for go-pastebin.
+//
+// The credentials are read from the PASTEBIN_USERNAME, PASTEBIN_PASSWORD and PASTEBIN_DEV_KEY environment variables.
+//
+// Usage:
+//
+//	pastebin sync [--dry-run] [--state FILE] [--prefix PREFIX] [--visibility VISIBILITY] [--expiration EXPIRATION] DIRECTORY
+//	pastebin update [--aliases FILE | --alias-paste TITLE] KEY_OR_ALIAS FILE
+//	pastebin alias [--aliases FILE | --alias-paste TITLE [--create]] [ALIAS [KEY]]
+//	pastebin diff [--context LINES] [--ignore-whitespace] [--side-by-side] [--width COLUMNS] KEY1 KEY2
+//	pastebin delete [--trash DIRECTORY] KEY...
+//	pastebin restore --trash DIRECTORY [KEY]
+//	pastebin retention [--dry-run] [--trash DIRECTORY] [--aliases FILE | --alias-paste TITLE] POLICY_FILE
+//	pastebin schedule --jobs FILE [--at TIME | --in DURATION] [--lifetime DURATION] [--title TITLE] [--syntax SYNTAX] [--visibility VISIBILITY] FILE
+//	pastebin scheduler --jobs FILE [--interval DURATION] [--once]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TwiN/go-pastebin"
+)
+
+var errUsage = errors.New("usage: pastebin <command> [flags] [arguments]\n\ncommands:\n  sync    mirror a directory to the pastes of the account\n  update  replace the content of a paste\n  alias   list, resolve or set the aliases of pastes\n  diff    compare the content of two pastes\n  delete  delete pastes, optionally moving them to a trash directory\n  restore list or restore the pastes of a trash directory\n  retention delete the pastes selected by a retention policy\n  schedule  schedule the publication and deletion of a paste\n  scheduler run the scheduled publications and deletions")
+
+type command func(arguments []string) error
+
+var commands = map[string]command{
+	"sync":      runSync,
+	"update":    runUpdate,
+	"alias":     runAlias,
+	"diff":      runDiff,
+	"delete":    runDelete,
+	"restore":   runRestore,
+	"retention": runRetention,
+	"schedule":  runSchedule,
+	"scheduler": runScheduler,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, errUsage)
+		os.Exit(2)
+	}
+	run, exists := commands[os.Args[1]]
+	if !exists {
+		fmt.Fprintln(os.Stderr, errUsage)
+		os.Exit(2)
+	}
+	if err := run(os.Args[2:]); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// newClient creates a client using the credentials from the environment
+func newClient() (*pastebin.Client, error) {
+	return pastebin.NewClient(os.Getenv("PASTEBIN_USERNAME"), os.Getenv("PASTEBIN_PASSWORD"), os.Getenv("PASTEBIN_DEV_KEY"))
+}
+
+func runSync(arguments []string) error {
+	flagSet := flag.NewFlagSet("sync", flag.ContinueOnError)
+	dryRun := flagSet.Bool("dry-run", false, "only print the planned changes")
+	stateFile := flagSet.String("state", "", "path of the state file (default DIRECTORY/"+pastebin.DefaultSyncStateFileName+")")
+	titlePrefix := flagSet.String("prefix", "", "prefix of the titles of the pastes; pastes with this prefix are pulled into the directory")
+	visibility := flagSet.String("visibility", "unlisted", "visibility of the pastes created (public, unlisted or private)")
+	expiration := flagSet.String("expiration", string(pastebin.ExpirationNever), "expiration of the pastes created")
+	flagSet.Usage = func() {
+		fmt.Fprintln(flagSet.Output(), "usage: pastebin sync [flags] DIRECTORY")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(arguments); err != nil {
+		return err
+	}
+	if flagSet.NArg() != 1 {
+		flagSet.Usage()
+		return flag.ErrHelp
+	}
+	parsedVisibility, err := pastebin.ParseVisibility(*visibility)
+	if err != nil {
+		return err
+	}
+	if !pastebin.Expiration(*expiration).IsValid() {
+		return fmt.Errorf("invalid expiration %q", *expiration)
+	}
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	plan, err := client.Sync(flagSet.Arg(0), pastebin.SyncOptions{
+		StateFile:   *stateFile,
+		TitlePrefix: *titlePrefix,
+		Visibility:  parsedVisibility,
+		Expiration:  pastebin.Expiration(*expiration),
+		DryRun:      *dryRun,
+	})
+	if plan != nil {
+		fmt.Print(plan)
+	}
+	return err
+}
+
+// registerAliasFlags registers the flags selecting the alias registry, and returns a function that configures the
+// registry on the client once the flags are parsed
+func registerAliasFlags(flagSet *flag.FlagSet) func(client *pastebin.Client) (*pastebin.AliasRegistry, error) {
+	aliasFile := flagSet.String("aliases", "", "path of the JSON file storing the aliases")
+	aliasPaste := flagSet.String("alias-paste", "", "title of the private paste storing the aliases")
+	return func(client *pastebin.Client) (*pastebin.AliasRegistry, error) {
+		var registry *pastebin.AliasRegistry
+		switch {
+		case len(*aliasFile) > 0 && len(*aliasPaste) > 0:
+			return nil, errors.New("--aliases and --alias-paste are mutually exclusive")
+		case len(*aliasFile) > 0:
+			registry = pastebin.NewFileAliasRegistry(*aliasFile)
+		case len(*aliasPaste) > 0:
+			registry = client.NewPasteAliasRegistry(*aliasPaste)
+		}
+		client.WithAliases(registry)
+		return registry, nil
+	}
+}
+
+func runUpdate(arguments []string) error {
+	flagSet := flag.NewFlagSet("update", flag.ContinueOnError)
+	configureAliases := registerAliasFlags(flagSet)
+	flagSet.Usage = func() {
+		fmt.Fprintln(flagSet.Output(), "usage: pastebin update [flags] KEY_OR_ALIAS FILE")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(arguments); err != nil {
+		return err
+	}
+	if flagSet.NArg() != 2 {
+		flagSet.Usage()
+		return flag.ErrHelp
+	}
+	content, err := os.ReadFile(flagSet.Arg(1))
+	if err != nil {
+		return err
+	}
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	if _, err = configureAliases(client); err != nil {
+		return err
+	}
+	pasteKey, err := client.ResolvePaste(flagSet.Arg(0))
+	if err != nil {
+		return err
+	}
+	newPasteKey, err := client.UpdatePaste(pasteKey, string(content))
+	if len(newPasteKey) > 0 {
+		fmt.Println(client.URLBuilder().ViewURL(newPasteKey))
+	}
+	return err
+}
+
+func runAlias(arguments []string) error {
+	flagSet := flag.NewFlagSet("alias", flag.ContinueOnError)
+	configureAliases := registerAliasFlags(flagSet)
+	create := flagSet.Bool("create", false, "create the paste selected by --alias-paste if it doesn't exist")
+	flagSet.Usage = func() {
+		fmt.Fprintln(flagSet.Output(), "usage: pastebin alias [flags] [ALIAS [KEY]]")
+		fmt.Fprintln(flagSet.Output(), "lists every alias, resolves ALIAS, or makes ALIAS point to KEY")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(arguments); err != nil {
+		return err
+	}
+	if flagSet.NArg() > 2 {
+		flagSet.Usage()
+		return flag.ErrHelp
+	}
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	registry, err := configureAliases(client)
+	if err != nil {
+		return err
+	}
+	if registry == nil {
+		return errors.New("either --aliases or --alias-paste must be specified")
+	}
+	if *create {
+		aliasPaste := flagSet.Lookup("alias-paste").Value.String()
+		if len(aliasPaste) == 0 {
+			return errors.New("--create requires --alias-paste")
+		}
+		if registry, err = client.CreatePasteAliasRegistry(aliasPaste); err != nil {
+			return err
+		}
+		client.WithAliases(registry)
+	}
+	switch flagSet.NArg() {
+	case 0:
+		aliases, err := registry.Aliases()
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(aliases))
+		for name := range aliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("%s\t%s\n", name, aliases[name])
+		}
+	case 1:
+		pasteKey, err := registry.Resolve(flagSet.Arg(0))
+		if err != nil {
+			return err
+		}
+		fmt.Println(pasteKey)
+	default:
+		pasteRef, err := pastebin.ParsePasteRef(flagSet.Arg(1))
+		if err != nil {
+			return err
+		}
+		return registry.Set(flagSet.Arg(0), pasteRef.Key)
+	}
+	return nil
+}
+
+func runDiff(arguments []string) error {
+	flagSet := flag.NewFlagSet("diff", flag.ContinueOnError)
+	configureAliases := registerAliasFlags(flagSet)
+	contextLines := flagSet.Int("context", pastebin.DefaultDiffContext, "number of unchanged lines shown around each change")
+	ignoreWhitespace := flagSet.Bool("ignore-whitespace", false, "ignore whitespace when comparing lines")
+	sideBySide := flagSet.Bool("side-by-side", false, "show the pastes in two columns")
+	width := flagSet.Int("width", pastebin.DefaultDiffWidth, "width of the output with --side-by-side")
+	flagSet.Usage = func() {
+		fmt.Fprintln(flagSet.Output(), "usage: pastebin diff [flags] KEY1 KEY2")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(arguments); err != nil {
+		return err
+	}
+	if flagSet.NArg() != 2 {
+		flagSet.Usage()
+		return flag.ErrHelp
+	}
+	// Unlike DiffOptions, where 0 means the default context, --context 0 means no context
+	if *contextLines == 0 {
+		*contextLines = -1
+	}
+	// Comparing public pastes does not require credentials
+	client := &pastebin.Client{}
+	if len(os.Getenv("PASTEBIN_USERNAME")) > 0 {
+		var err error
+		if client, err = newClient(); err != nil {
+			return err
+		}
+	}
+	if _, err := configureAliases(client); err != nil {
+		return err
+	}
+	fromPasteKey, err := client.ResolvePaste(flagSet.Arg(0))
+	if err != nil {
+		return err
+	}
+	toPasteKey, err := client.ResolvePaste(flagSet.Arg(1))
+	if err != nil {
+		return err
+	}
+	diff, err := client.DiffPastes(fromPasteKey, toPasteKey, pastebin.DiffOptions{
+		Context:          *contextLines,
+		IgnoreWhitespace: *ignoreWhitespace,
+		SideBySide:       *sideBySide,
+		Width:            *width,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Print(diff)
+	return nil
+}
+
+func runDelete(arguments []string) error {
+	flagSet := flag.NewFlagSet("delete", flag.ContinueOnError)
+	trashDirectory := flagSet.String("trash", "", "directory in which the pastes are stored before being deleted")
+	flagSet.Usage = func() {
+		fmt.Fprintln(flagSet.Output(), "usage: pastebin delete [flags] KEY...")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(arguments); err != nil {
+		return err
+	}
+	if flagSet.NArg() == 0 {
+		flagSet.Usage()
+		return flag.ErrHelp
+	}
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	if len(*trashDirectory) > 0 {
+		client.WithTrash(pastebin.NewTrash(*trashDirectory))
+	}
+	var errs []error
+	for _, pasteKey := range flagSet.Args() {
+		if err = client.DeletePaste(pasteKey); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", pasteKey, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func runRestore(arguments []string) error {
+	flagSet := flag.NewFlagSet("restore", flag.ContinueOnError)
+	trashDirectory := flagSet.String("trash", "", "directory in which the pastes were stored before being deleted")
+	flagSet.Usage = func() {
+		fmt.Fprintln(flagSet.Output(), "usage: pastebin restore --trash DIRECTORY [KEY]")
+		fmt.Fprintln(flagSet.Output(), "lists the pastes in the trash, or restores KEY")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(arguments); err != nil {
+		return err
+	}
+	if len(*trashDirectory) == 0 || flagSet.NArg() > 1 {
+		flagSet.Usage()
+		return flag.ErrHelp
+	}
+	trash := pastebin.NewTrash(*trashDirectory)
+	if flagSet.NArg() == 0 {
+		trashedPastes, err := trash.List()
+		if err != nil {
+			return err
+		}
+		for _, trashedPaste := range trashedPastes {
+			fmt.Printf("%s\t%s\t%s\n", trashedPaste.Key, trashedPaste.DeletedAt.Local().Format("2006-01-02 15:04:05"), trashedPaste.Title)
+		}
+		return nil
+	}
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	newPasteKey, err := client.WithTrash(trash).Restore(flagSet.Arg(0))
+	if len(newPasteKey) > 0 {
+		fmt.Println(client.URLBuilder().ViewURL(newPasteKey))
+	}
+	return err
+}
+
+func runRetention(arguments []string) error {
+	flagSet := flag.NewFlagSet("retention", flag.ContinueOnError)
+	configureAliases := registerAliasFlags(flagSet)
+	dryRun := flagSet.Bool("dry-run", false, "only print the pastes that would be deleted")
+	trashDirectory := flagSet.String("trash", "", "directory in which the pastes are stored before being deleted")
+	flagSet.Usage = func() {
+		fmt.Fprintln(flagSet.Output(), "usage: pastebin retention [flags] POLICY_FILE")
+		fmt.Fprintln(flagSet.Output(), "pastes that an alias points to are never deleted")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(arguments); err != nil {
+		return err
+	}
+	if flagSet.NArg() != 1 {
+		flagSet.Usage()
+		return flag.ErrHelp
+	}
+	policy, err := pastebin.LoadRetentionPolicy(flagSet.Arg(0))
+	if err != nil {
+		return err
+	}
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	if _, err = configureAliases(client); err != nil {
+		return err
+	}
+	if len(*trashDirectory) > 0 {
+		client.WithTrash(pastebin.NewTrash(*trashDirectory))
+	}
+	report, err := client.ApplyRetentionPolicy(policy, pastebin.RetentionOptions{DryRun: *dryRun})
+	if report != nil {
+		fmt.Print(report)
+	}
+	return err
+}
+
+// parseDuration parses a duration like time.ParseDuration, but also supports a number of days such as 45d
+func parseDuration(value string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		if numberOfDays, err := strconv.Atoi(days); err == nil {
+			return time.Duration(numberOfDays) * 24 * time.Hour, nil
+		}
+	}
+	return time.ParseDuration(value)
+}
+
+func runSchedule(arguments []string) error {
+	flagSet := flag.NewFlagSet("schedule", flag.ContinueOnError)
+	jobsFile := flagSet.String("jobs", "", "path of the file storing the scheduled jobs")
+	at := flagSet.String("at", "", "time at which the paste is published, in the RFC 3339 format (default now)")
+	in := flagSet.String("in", "", "duration after which the paste is published, e.g. 2h or 3d")
+	lifetime := flagSet.String("lifetime", "", "duration after which the paste is deleted, e.g. 3h or 45d (default never)")
+	title := flagSet.String("title", "", "title of the paste (default the name of the file)")
+	syntax := flagSet.String("syntax", "text", "syntax of the paste")
+	visibility := flagSet.String("visibility", "unlisted", "visibility of the paste (public, unlisted or private)")
+	flagSet.Usage = func() {
+		fmt.Fprintln(flagSet.Output(), "usage: pastebin schedule [flags] FILE")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(arguments); err != nil {
+		return err
+	}
+	if len(*jobsFile) == 0 || flagSet.NArg() != 1 || (len(*at) > 0 && len(*in) > 0) {
+		flagSet.Usage()
+		return flag.ErrHelp
+	}
+	publishAt := time.Now()
+	if len(*at) > 0 {
+		var err error
+		if publishAt, err = time.Parse(time.RFC3339, *at); err != nil {
+			return err
+		}
+	} else if len(*in) > 0 {
+		delay, err := parseDuration(*in)
+		if err != nil {
+			return err
+		}
+		publishAt = publishAt.Add(delay)
+	}
+	var parsedLifetime time.Duration
+	if len(*lifetime) > 0 {
+		var err error
+		if parsedLifetime, err = parseDuration(*lifetime); err != nil {
+			return err
+		}
+	}
+	parsedVisibility, err := pastebin.ParseVisibility(*visibility)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(flagSet.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(*title) == 0 {
+		*title = filepath.Base(flagSet.Arg(0))
+	}
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	scheduler, err := client.NewScheduler(*jobsFile, pastebin.SchedulerOptions{})
+	if err != nil {
+		return err
+	}
+	job, err := scheduler.Schedule(pastebin.NewCreatePasteRequest(*title, string(content), pastebin.ExpirationNever, parsedVisibility, *syntax), publishAt, parsedLifetime)
+	if err != nil {
+		return err
+	}
+	fmt.Println(job.ID)
+	return nil
+}
+
+func runScheduler(arguments []string) error {
+	flagSet := flag.NewFlagSet("scheduler", flag.ContinueOnError)
+	jobsFile := flagSet.String("jobs", "", "path of the file storing the scheduled jobs")
+	interval := flagSet.Duration("interval", time.Minute, "interval at which the jobs are checked")
+	once := flagSet.Bool("once", false, "run the pending jobs once and exit")
+	flagSet.Usage = func() {
+		fmt.Fprintln(flagSet.Output(), "usage: pastebin scheduler [flags]")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(arguments); err != nil {
+		return err
+	}
+	if len(*jobsFile) == 0 || flagSet.NArg() != 0 {
+		flagSet.Usage()
+		return flag.ErrHelp
+	}
+	if *interval <= 0 {
+		return errors.New("--interval must be positive")
+	}
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	scheduler, err := client.NewScheduler(*jobsFile, pastebin.SchedulerOptions{})
+	if err != nil {
+		return err
+	}
+	printReport := func(report *pastebin.SchedulerReport, err error) {
+		for _, job := range report.Published {
+			fmt.Printf("published %s as %s\n", job.ID, client.URLBuilder().ViewURL(job.PasteKey))
+		}
+		for _, job := range report.Deleted {
+			fmt.Printf("deleted %s (%s)\n", job.ID, job.PasteKey)
+		}
+		for _, job := range report.Failed {
+			fmt.Printf("removed %s, which can never be published: %s\n", job.ID, job.LastError)
+		}
+		for _, missed := range report.Missed {
+			if missed.PerformedAt.IsZero() {
+				fmt.Printf("missed the %s deadline of %s (%s), skipped\n", missed.Action, missed.JobID, missed.Deadline.Local().Format(time.RFC3339))
+			} else {
+				fmt.Printf("missed the %s deadline of %s (%s) by %s\n", missed.Action, missed.JobID, missed.Deadline.Local().Format(time.RFC3339), missed.PerformedAt.Sub(missed.Deadline).Round(time.Second))
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+	}
+	if *once {
+		report, err := scheduler.RunPending()
+		printReport(report, err)
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err = scheduler.Run(ctx, *interval, printReport); errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
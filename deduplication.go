@@ -0,0 +1,78 @@
+package pastebin
+
+import (
+	"errors"
+	"sync"
+)
+
+// errInflightCallPanicked is returned to the callers waiting for a call that panicked
+var errInflightCallPanicked = errors.New("the request in flight panicked")
+
+// requestGroup collapses concurrent calls sharing the same key into a single call
+type requestGroup struct {
+	calls map[string]*inflightCall
+	mutex sync.Mutex
+
+	// joined, if set, is called every time a call joins a call already in flight, and is only set in tests
+	joined func(key string)
+}
+
+type inflightCall struct {
+	waitGroup sync.WaitGroup
+	body      []byte
+	err       error
+}
+
+// do calls fn and returns its result, unless a call with the same key is already in flight, in which case it waits
+// for that call to complete and returns its result instead
+func (g *requestGroup) do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mutex.Lock()
+	if call, exists := g.calls[key]; exists {
+		g.mutex.Unlock()
+		if g.joined != nil {
+			g.joined(key)
+		}
+		call.waitGroup.Wait()
+		return call.body, call.err
+	}
+	call := &inflightCall{}
+	call.waitGroup.Add(1)
+	g.calls[key] = call
+	g.mutex.Unlock()
+
+	// The call is completed even if fn panics, so that the callers waiting for it are released
+	defer func() {
+		g.mutex.Lock()
+		delete(g.calls, key)
+		g.mutex.Unlock()
+		call.waitGroup.Done()
+	}()
+	// If fn panics, the error is left as is for the callers waiting for it
+	call.err = errInflightCallPanicked
+	call.body, call.err = fn()
+	return call.body, call.err
+}
+
+// WithRequestDeduplication configures whether concurrent identical read requests should be collapsed into a single
+// HTTP request, with every caller sharing the result or the error of that request.
+//
+// This applies to GetPasteContent, GetPasteContentUsingScrapingAPI, GetPasteUsingScrapingAPI and
+// GetRecentPastesUsingScrapingAPI, and must be configured before the client is used concurrently.
+//
+// Returns the client to allow chaining
+func (c *Client) WithRequestDeduplication(enabled bool) *Client {
+	if enabled {
+		c.requestGroup = &requestGroup{calls: make(map[string]*inflightCall)}
+	} else {
+		c.requestGroup = nil
+	}
+	return c
+}
+
+// deduplicate calls fn through the request group of the client if request deduplication is enabled
+func (c *Client) deduplicate(operation Operation, requestUrl string, fn func() ([]byte, error)) ([]byte, error) {
+	if c.requestGroup == nil {
+		return fn()
+	}
+	return c.requestGroup.do(string(operation)+" "+requestUrl, fn)
+}
@@ -0,0 +1,98 @@
+package pastebin
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func TestClient_WithRequestDeduplication(t *testing.T) {
+	const numberOfCallers = 10
+	var numberOfRequests atomic.Int32
+	joined := make(chan struct{}, numberOfCallers)
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		numberOfRequests.Add(1)
+		// Every other caller must have joined the request in flight before it completes
+		for i := 0; i < numberOfCallers-1; i++ {
+			<-joined
+		}
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("this is code"))}
+	})}
+	client, _ := NewClient("", "", "token")
+	client.WithRequestDeduplication(true)
+	client.requestGroup.joined = func(key string) {
+		joined <- struct{}{}
+	}
+	var waitGroup sync.WaitGroup
+	results := make([]string, numberOfCallers)
+	for i := range results {
+		waitGroup.Add(1)
+		go func(i int) {
+			defer waitGroup.Done()
+			results[i], _ = client.GetPasteContentUsingScrapingAPI("abcdefgh")
+		}(i)
+	}
+	waitGroup.Wait()
+	if numberOfRequests.Load() != 1 {
+		t.Errorf("expected %d request, got %d", 1, numberOfRequests.Load())
+	}
+	for _, result := range results {
+		if result != "this is code" {
+			t.Errorf("expected '%s', got '%s'", "this is code", result)
+		}
+	}
+}
+
+func TestRequestGroupSharesErrors(t *testing.T) {
+	group := &requestGroup{calls: make(map[string]*inflightCall)}
+	_, err := group.do("key", func() ([]byte, error) {
+		return nil, io.ErrUnexpectedEOF
+	})
+	if err != io.ErrUnexpectedEOF {
+		t.Error("expected io.ErrUnexpectedEOF, got", err)
+	}
+	if len(group.calls) != 0 {
+		t.Error("calls should've been removed from the group once completed")
+	}
+}
+
+func TestRequestGroupReleasesWaitersOnPanic(t *testing.T) {
+	group := &requestGroup{calls: make(map[string]*inflightCall)}
+	joined := make(chan struct{})
+	group.joined = func(key string) {
+		close(joined)
+	}
+	waiterErr := make(chan error)
+	panicked := make(chan any)
+	go func() {
+		defer func() {
+			panicked <- recover()
+		}()
+		_, _ = group.do("key", func() ([]byte, error) {
+			// The call panics only once another caller has joined it
+			go func() {
+				_, err := group.do("key", func() ([]byte, error) {
+					return nil, nil
+				})
+				waiterErr <- err
+			}()
+			<-joined
+			panic("boom")
+		})
+	}()
+	if recovered := <-panicked; recovered != "boom" {
+		t.Errorf("expected the panic to be propagated to the caller, got %v", recovered)
+	}
+	if err := <-waiterErr; !errors.Is(err, errInflightCallPanicked) {
+		t.Error("expected the waiter to be released with errInflightCallPanicked, got", err)
+	}
+	if len(group.calls) != 0 {
+		t.Error("calls should've been removed from the group once completed")
+	}
+}
@@ -0,0 +1,405 @@
+package pastebin
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultDiffContext is the number of unchanged lines shown around each change by default, like diff -u
+	DefaultDiffContext = 3
+
+	// DefaultDiffWidth is the default width of the output of side-by-side diffs
+	DefaultDiffWidth = 130
+)
+
+// DiffOptions are the options of Diff and DiffPastes
+type DiffOptions struct {
+	// Context is the number of unchanged lines shown around each change. Defaults to DefaultDiffContext if 0, and no
+	// unchanged line is shown if negative.
+	Context int
+
+	// IgnoreWhitespace ignores whitespace when comparing lines, like diff -w
+	IgnoreWhitespace bool
+
+	// SideBySide shows the two texts in columns rather than in the unified format, like diff -y
+	SideBySide bool
+
+	// Width is the width of the output when SideBySide is true. Defaults to DefaultDiffWidth.
+	Width int
+}
+
+type diffOperation int
+
+const (
+	diffEqual diffOperation = iota
+	diffDelete
+	diffInsert
+)
+
+// diffEdit is a single line of an edit script. fromIndex and toIndex are the positions of the line in each text, which
+// for insertions and deletions is the position in the other text at which the line was inserted or deleted.
+type diffEdit struct {
+	operation          diffOperation
+	fromIndex, toIndex int
+}
+
+// UnifiedDiff returns the differences between two texts in the unified format, with context unchanged lines around
+// each change. Returns an empty string if the texts have the same lines.
+//
+// Lines are compared without their line endings, so texts that only differ in their line endings are equal.
+func UnifiedDiff(fromName, toName, from, to string, context int) string {
+	fromLines, toLines := splitLines(from), splitLines(to)
+	return formatUnifiedDiff(fromName, toName, fromLines, toLines, diffLines(fromLines, toLines), context)
+}
+
+// Diff returns the differences between two texts in the unified format, or in columns if options.SideBySide is true.
+// Returns an empty string if the texts have the same lines.
+//
+// Lines are compared without their line endings, so texts that only differ in their line endings are equal.
+func Diff(fromName, toName, from, to string, options DiffOptions) string {
+	context := options.Context
+	if context == 0 {
+		context = DefaultDiffContext
+	}
+	fromLines, toLines := splitLines(from), splitLines(to)
+	fromKeys, toKeys := fromLines, toLines
+	if options.IgnoreWhitespace {
+		fromKeys, toKeys = removeWhitespace(fromLines), removeWhitespace(toLines)
+	}
+	edits := diffLines(fromKeys, toKeys)
+	if options.SideBySide {
+		width := options.Width
+		if width <= 0 {
+			width = DefaultDiffWidth
+		}
+		return formatSideBySideDiff(fromName, toName, fromLines, toLines, edits, context, width)
+	}
+	return formatUnifiedDiff(fromName, toName, fromLines, toLines, edits, context)
+}
+
+// removeWhitespace returns a copy of the lines without any whitespace
+func removeWhitespace(lines []string) []string {
+	keys := make([]string, len(lines))
+	for i, line := range lines {
+		keys[i] = strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return -1
+			}
+			return r
+		}, line)
+	}
+	return keys
+}
+
+// splitLines splits a text into lines, without their line endings
+func splitLines(text string) []string {
+	if len(text) == 0 {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// diffLines returns the shortest edit script transforming from into to
+func diffLines(from, to []string) []diffEdit {
+	// Lines are replaced by identifiers so that they are only compared once
+	identifiers := make(map[string]int)
+	identify := func(lines []string) []int {
+		ids := make([]int, len(lines))
+		for i, line := range lines {
+			id, exists := identifiers[line]
+			if !exists {
+				id = len(identifiers)
+				identifiers[line] = id
+			}
+			ids[i] = id
+		}
+		return ids
+	}
+	differ := &lineDiffer{from: identify(from), to: identify(to)}
+	differ.diff(0, len(from), 0, len(to))
+	return differ.edits
+}
+
+// lineDiffer implements the linear space variant of Myers' diff algorithm, which recursively splits the texts at the
+// middle snake of the shortest edit script
+type lineDiffer struct {
+	from, to []int
+	edits    []diffEdit
+}
+
+func (d *lineDiffer) diff(fromStart, fromEnd, toStart, toEnd int) {
+	for fromStart < fromEnd && toStart < toEnd && d.from[fromStart] == d.to[toStart] {
+		d.edits = append(d.edits, diffEdit{operation: diffEqual, fromIndex: fromStart, toIndex: toStart})
+		fromStart++
+		toStart++
+	}
+	suffixLength := 0
+	for fromEnd-suffixLength > fromStart && toEnd-suffixLength > toStart && d.from[fromEnd-suffixLength-1] == d.to[toEnd-suffixLength-1] {
+		suffixLength++
+	}
+	fromEnd -= suffixLength
+	toEnd -= suffixLength
+	if fromStart < fromEnd && toStart < toEnd {
+		if x, y, found := d.middleSnake(fromStart, fromEnd, toStart, toEnd); found {
+			d.diff(fromStart, fromStart+x, toStart, toStart+y)
+			d.diff(fromStart+x, fromEnd, toStart+y, toEnd)
+			fromStart, toStart = fromEnd, toEnd
+		}
+	}
+	for ; fromStart < fromEnd; fromStart++ {
+		d.edits = append(d.edits, diffEdit{operation: diffDelete, fromIndex: fromStart, toIndex: toStart})
+	}
+	for ; toStart < toEnd; toStart++ {
+		d.edits = append(d.edits, diffEdit{operation: diffInsert, fromIndex: fromEnd, toIndex: toStart})
+	}
+	for i := 0; i < suffixLength; i++ {
+		d.edits = append(d.edits, diffEdit{operation: diffEqual, fromIndex: fromEnd + i, toIndex: toEnd + i})
+	}
+}
+
+// middleSnake searches for the shortest edit script of the ranges from both ends at the same time, and returns the
+// point, relative to the start of the ranges, at which the two searches overlap. Returns false if the ranges have no
+// line in common.
+func (d *lineDiffer) middleSnake(fromStart, fromEnd, toStart, toEnd int) (int, int, bool) {
+	from, to := d.from[fromStart:fromEnd], d.to[toStart:toEnd]
+	n, m := len(from), len(to)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+	delta := n - m
+	// If the difference of length is odd, the paths overlap during the forward search, and otherwise during the
+	// backward search
+	oddDelta := delta%2 != 0
+	var forwardStart, forwardEnd, backwardStart, backwardEnd int
+	for step := 0; step < maxD; step++ {
+		for k := -step + forwardStart; k <= step-forwardEnd; k += 2 {
+			var x int
+			if k == -step || (k != step && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && from[x] == to[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			switch {
+			case x > n:
+				forwardEnd += 2
+			case y > m:
+				forwardStart += 2
+			case oddDelta:
+				if backwardIndex := offset + delta - k; backwardIndex >= 0 && backwardIndex < len(backward) && backward[backwardIndex] != -1 && x >= n-backward[backwardIndex] {
+					return x, y, true
+				}
+			}
+		}
+		for k := -step + backwardStart; k <= step-backwardEnd; k += 2 {
+			var x int
+			if k == -step || (k != step && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && from[n-x-1] == to[m-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			switch {
+			case x > n:
+				backwardEnd += 2
+			case y > m:
+				backwardStart += 2
+			case !oddDelta:
+				if forwardIndex := offset + delta - k; forwardIndex >= 0 && forwardIndex < len(forward) && forward[forwardIndex] != -1 {
+					forwardX := forward[forwardIndex]
+					if forwardX >= n-x {
+						return forwardX, offset + forwardX - forwardIndex, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// formatUnifiedDiff formats an edit script in the unified format
+func formatUnifiedDiff(fromName, toName string, fromLines, toLines []string, edits []diffEdit, context int) string {
+	context = max(context, 0)
+	var output strings.Builder
+	for _, hunk := range diffHunks(edits, context) {
+		if output.Len() == 0 {
+			fmt.Fprintf(&output, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		var fromCount, toCount int
+		for _, edit := range hunk {
+			if edit.operation != diffInsert {
+				fromCount++
+			}
+			if edit.operation != diffDelete {
+				toCount++
+			}
+		}
+		fmt.Fprintf(&output, "@@ -%s +%s @@\n", formatHunkRange(hunk[0].fromIndex, fromCount), formatHunkRange(hunk[0].toIndex, toCount))
+		for _, edit := range hunk {
+			switch edit.operation {
+			case diffEqual:
+				output.WriteString(" " + fromLines[edit.fromIndex] + "\n")
+			case diffDelete:
+				output.WriteString("-" + fromLines[edit.fromIndex] + "\n")
+			case diffInsert:
+				output.WriteString("+" + toLines[edit.toIndex] + "\n")
+			}
+		}
+	}
+	return output.String()
+}
+
+// formatSideBySideDiff formats an edit script in two columns. Lines that were deleted are marked with <, lines that
+// were inserted with >, and lines that were replaced with |.
+func formatSideBySideDiff(fromName, toName string, fromLines, toLines []string, edits []diffEdit, context, width int) string {
+	context = max(context, 0)
+	columnWidth := max((width-3)/2, 1)
+	var output strings.Builder
+	writeRow := func(left string, marker byte, right string) {
+		left = fitColumn(left, columnWidth)
+		output.WriteString(left)
+		if marker != ' ' || len(right) > 0 {
+			output.WriteString(strings.Repeat(" ", columnWidth-utf8.RuneCountInString(left)))
+			output.WriteByte(' ')
+			output.WriteByte(marker)
+			output.WriteByte(' ')
+			output.WriteString(fitColumn(right, columnWidth))
+		}
+		output.WriteByte('\n')
+	}
+	for _, hunk := range diffHunks(edits, context) {
+		if output.Len() == 0 {
+			writeRow(fromName, ' ', toName)
+		}
+		var fromCount, toCount int
+		for _, edit := range hunk {
+			if edit.operation != diffInsert {
+				fromCount++
+			}
+			if edit.operation != diffDelete {
+				toCount++
+			}
+		}
+		fmt.Fprintf(&output, "@@ -%s +%s @@\n", formatHunkRange(hunk[0].fromIndex, fromCount), formatHunkRange(hunk[0].toIndex, toCount))
+		for i := 0; i < len(hunk); {
+			if hunk[i].operation == diffEqual {
+				writeRow(fromLines[hunk[i].fromIndex], ' ', toLines[hunk[i].toIndex])
+				i++
+				continue
+			}
+			// Deleted lines are paired with the lines inserted in their place
+			var deleted, inserted []string
+			for ; i < len(hunk) && hunk[i].operation != diffEqual; i++ {
+				if hunk[i].operation == diffDelete {
+					deleted = append(deleted, fromLines[hunk[i].fromIndex])
+				} else {
+					inserted = append(inserted, toLines[hunk[i].toIndex])
+				}
+			}
+			for j := 0; j < max(len(deleted), len(inserted)); j++ {
+				switch {
+				case j < len(deleted) && j < len(inserted):
+					writeRow(deleted[j], '|', inserted[j])
+				case j < len(deleted):
+					writeRow(deleted[j], '<', "")
+				default:
+					writeRow("", '>', inserted[j])
+				}
+			}
+		}
+	}
+	return output.String()
+}
+
+// fitColumn expands the tabs of a line and truncates it to the width of a column
+func fitColumn(line string, width int) string {
+	line = strings.ReplaceAll(line, "\t", "    ")
+	if utf8.RuneCountInString(line) <= width {
+		return line
+	}
+	return string([]rune(line)[:width])
+}
+
+// diffHunks groups the changes of an edit script into hunks with context unchanged lines around each change, merging
+// changes that are separated by 2*context unchanged lines or less
+func diffHunks(edits []diffEdit, context int) [][]diffEdit {
+	var hunks [][]diffEdit
+	for i := 0; i < len(edits); {
+		if edits[i].operation == diffEqual {
+			i++
+			continue
+		}
+		start, end := max(0, i-context), i
+		for {
+			for end < len(edits) && edits[end].operation != diffEqual {
+				end++
+			}
+			nextChange := end
+			for nextChange < len(edits) && edits[nextChange].operation == diffEqual {
+				nextChange++
+			}
+			if nextChange < len(edits) && nextChange-end <= 2*context {
+				end = nextChange
+				continue
+			}
+			end = min(len(edits), end+context)
+			break
+		}
+		hunks = append(hunks, edits[start:end])
+		i = end
+	}
+	return hunks
+}
+
+// formatHunkRange formats the range of lines of a hunk, where start is the 0-based position of the first line
+func formatHunkRange(start, count int) string {
+	switch count {
+	case 0:
+		// An empty range refers to the line before the position
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+// DiffPastes returns the differences between the content of two pastes.
+// See Client.DiffPastes for more information.
+func DiffPastes(fromPasteKey, toPasteKey string, options DiffOptions) (string, error) {
+	return (&Client{}).DiffPastes(fromPasteKey, toPasteKey, options)
+}
+
+// DiffPastes returns the differences between the content of two pastes, formatted according to options (see Diff).
+//
+// The content of each paste is retrieved with GetUserPasteContent if the client is authenticated and the paste
+// belongs to the user, and with GetPasteContent otherwise, so private pastes can only be compared by their owner.
+func (c *Client) DiffPastes(fromPasteKey, toPasteKey string, options DiffOptions) (string, error) {
+	results, err := runBulk([]string{fromPasteKey, toPasteKey}, 2, c.fetchPasteContent)
+	if err != nil {
+		return "", err
+	}
+	return Diff(fromPasteKey, toPasteKey, results[fromPasteKey].Value, results[toPasteKey].Value, options), nil
+}
@@ -0,0 +1,180 @@
+package pastebin
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		from, to string
+		context  int
+		expected string
+	}{
+		{
+			name:     "equal",
+			from:     "a\nb\n",
+			to:       "a\r\nb",
+			context:  3,
+			expected: "",
+		},
+		{
+			name:    "modified-line",
+			from:    "a\nb\nc\nd\ne\nf\ng\nh\n",
+			to:      "a\nb\nc\nd\nE\nf\ng\nh\n",
+			context: 2,
+			expected: "--- from\n+++ to\n" +
+				"@@ -3,5 +3,5 @@\n c\n d\n-e\n+E\n f\n g\n",
+		},
+		{
+			name:    "separate-hunks",
+			from:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			to:      "0\n1\n2\n3\n4\n5\n6\n7\n8\n",
+			context: 1,
+			expected: "--- from\n+++ to\n" +
+				"@@ -1 +1,2 @@\n+0\n 1\n" +
+				"@@ -8,2 +9 @@\n 8\n-9\n",
+		},
+		{
+			name:    "merged-hunks",
+			from:    "1\n2\n3\n4\n5\n",
+			to:      "1\nx\n3\ny\n5\n",
+			context: 1,
+			expected: "--- from\n+++ to\n" +
+				"@@ -1,5 +1,5 @@\n 1\n-2\n+x\n 3\n-4\n+y\n 5\n",
+		},
+		{
+			name:    "from-empty",
+			from:    "",
+			to:      "a\nb\n",
+			context: 3,
+			expected: "--- from\n+++ to\n" +
+				"@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:    "no-context",
+			from:    "a\nb\nc\n",
+			to:      "a\nc\n",
+			context: -1,
+			expected: "--- from\n+++ to\n" +
+				"@@ -2 +1,0 @@\n-b\n",
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			if actual := UnifiedDiff("from", "to", scenario.from, scenario.to, scenario.context); actual != scenario.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", scenario.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDiffLinesIsMinimal(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, random.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + random.Intn(4)))
+		}
+		return lines
+	}
+	for i := 0; i < 500; i++ {
+		from, to := randomLines(), randomLines()
+		edits := diffLines(from, to)
+		var rebuiltFrom, rebuiltTo []string
+		changes := 0
+		for _, edit := range edits {
+			switch edit.operation {
+			case diffEqual:
+				if from[edit.fromIndex] != to[edit.toIndex] {
+					t.Fatalf("lines %d and %d aren't equal", edit.fromIndex, edit.toIndex)
+				}
+				rebuiltFrom = append(rebuiltFrom, from[edit.fromIndex])
+				rebuiltTo = append(rebuiltTo, to[edit.toIndex])
+			case diffDelete:
+				rebuiltFrom = append(rebuiltFrom, from[edit.fromIndex])
+				changes++
+			case diffInsert:
+				rebuiltTo = append(rebuiltTo, to[edit.toIndex])
+				changes++
+			}
+		}
+		if strings.Join(rebuiltFrom, "") != strings.Join(from, "") || strings.Join(rebuiltTo, "") != strings.Join(to, "") {
+			t.Fatalf("the edit script of %v and %v doesn't rebuild them", from, to)
+		}
+		// The shortest edit script has as many changes as lines that aren't part of the longest common subsequence
+		if expected := len(from) + len(to) - 2*longestCommonSubsequenceLength(from, to); changes != expected {
+			t.Fatalf("expected %d changes between %v and %v, got %d", expected, from, to, changes)
+		}
+	}
+}
+
+func longestCommonSubsequenceLength(a, b []string) int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	return lengths[0][0]
+}
+
+func TestDiff(t *testing.T) {
+	from := "a\nb\nc\nd\ne\nf\ng\nh\n"
+	to := "a\nb\nc\nd  \nE\nf\ng\nh\n"
+	if expected, actual := "--- from\n+++ to\n@@ -1,8 +1,8 @@\n a\n b\n c\n-d\n-e\n+d  \n+E\n f\n g\n h\n", Diff("from", "to", from, to, DiffOptions{}); actual != expected {
+		t.Errorf("expected the default context to be used, got:\n%s", actual)
+	}
+	if expected, actual := "--- from\n+++ to\n@@ -5 +5 @@\n-e\n+E\n", Diff("from", "to", from, to, DiffOptions{Context: -1, IgnoreWhitespace: true}); actual != expected {
+		t.Errorf("expected whitespace to be ignored, got:\n%s", actual)
+	}
+	if actual := Diff("from", "to", "a b\n", "a\tb \n", DiffOptions{IgnoreWhitespace: true}); actual != "" {
+		t.Errorf("expected no difference, got:\n%s", actual)
+	}
+}
+
+func TestDiff_SideBySide(t *testing.T) {
+	actual := Diff("from", "to", "a\nb\nc\nd\ne\nf\n", "a\nB\nc\nd\ne\nf\ng\n", DiffOptions{Context: 1, SideBySide: true, Width: 13})
+	expected := "" +
+		"from    to\n" +
+		"@@ -1,3 +1,3 @@\n" +
+		"a       a\n" +
+		"b     | B\n" +
+		"c       c\n" +
+		"@@ -6 +6,2 @@\n" +
+		"f       f\n" +
+		"      > g\n"
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+	if actual = Diff("from", "to", "abcdefghij\n", "x\n", DiffOptions{SideBySide: true, Width: 13}); !strings.Contains(actual, "abcde | x\n") {
+		t.Errorf("expected long lines to be truncated, got:\n%s", actual)
+	}
+}
+
+func TestClient_DiffPastes(t *testing.T) {
+	account := newMockAccount()
+	account.pastes["paste001"] = &mockAccountPaste{title: "config", content: "a=1\nb=2\n"}
+	account.pastes["paste002"] = &mockAccountPaste{title: "config", content: "a=1\nb=3\n"}
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	diff, err := client.DiffPastes("paste001", "paste002", DiffOptions{})
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if expected := "--- paste001\n+++ paste002\n@@ -1,2 +1,2 @@\n a=1\n-b=2\n+b=3\n"; diff != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, diff)
+	}
+	if _, err = client.DiffPastes("paste001", "paste404", DiffOptions{}); err == nil {
+		t.Error("should've returned an error, because the second paste doesn't exist")
+	}
+}
@@ -0,0 +1,262 @@
+package pastebin
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	encryptedEnvelopeHeader  = "-----BEGIN PASTEBIN ENCRYPTED PASTE-----"
+	encryptedEnvelopeFooter  = "-----END PASTEBIN ENCRYPTED PASTE-----"
+	encryptedEnvelopeVersion = 1
+
+	encryptionKeySize  = 32
+	encryptionSaltSize = 16
+	cipherAES256GCM    = "aes-256-gcm"
+	kdfNone            = "none"
+	kdfPBKDF2SHA256    = "pbkdf2-sha256"
+)
+
+// DefaultPassphraseIterations is the number of PBKDF2-HMAC-SHA256 iterations used to derive a key from a passphrase
+const DefaultPassphraseIterations = 600000
+
+// maxPassphraseIterations is the maximum number of iterations accepted when decrypting an envelope, since the number
+// of iterations is read from the paste, and anyone able to create a paste could otherwise make decryption hang
+const maxPassphraseIterations = 10 * DefaultPassphraseIterations
+
+var (
+	ErrInvalidEncryptedEnvelope      = errors.New("paste is not a valid encrypted envelope")
+	ErrUnsupportedEnvelopeVersion    = errors.New("unsupported encrypted envelope version")
+	ErrMissingDecryptionKey          = errors.New("a key or a passphrase is required to decrypt this paste")
+	ErrDecryptionFailed              = errors.New("failed to decrypt paste: wrong key or passphrase, or the paste was tampered with")
+	ErrInvalidEncryptedPasteRef      = errors.New("invalid encrypted paste reference")
+	ErrEncryptedPasteRequiresContent = errors.New("cannot encrypt a paste without content")
+)
+
+// EncryptedPasteRef is a reference to an encrypted paste
+//
+// The Secret is never sent to Pastebin, so sharing the paste requires sharing the reference, which can be
+// converted to a string with EncryptedPasteRef.String and parsed back with ParseEncryptedPasteRef.
+type EncryptedPasteRef struct {
+	// Key is the key of the paste containing the encrypted envelope
+	Key string
+
+	// Secret is the randomly generated key used to encrypt the paste.
+	// It is nil if the paste was encrypted with a passphrase, in which case the passphrase must be shared separately.
+	Secret []byte
+}
+
+// String returns the shareable form of the reference, which is the paste key followed by a '#' and the
+// base64url-encoded secret, if there is one (e.g. abcdefgh#c2VjcmV0...)
+func (r *EncryptedPasteRef) String() string {
+	if len(r.Secret) == 0 {
+		return r.Key
+	}
+	return r.Key + "#" + base64.RawURLEncoding.EncodeToString(r.Secret)
+}
+
+// ParseEncryptedPasteRef parses a reference previously returned by EncryptedPasteRef.String
+func ParseEncryptedPasteRef(ref string) (*EncryptedPasteRef, error) {
+	key, encodedSecret, hasSecret := strings.Cut(strings.TrimSpace(ref), "#")
+	if len(key) == 0 {
+		return nil, ErrInvalidEncryptedPasteRef
+	}
+	encryptedPasteRef := &EncryptedPasteRef{Key: key}
+	if hasSecret {
+		secret, err := base64.RawURLEncoding.DecodeString(encodedSecret)
+		if err != nil || len(secret) != encryptionKeySize {
+			return nil, ErrInvalidEncryptedPasteRef
+		}
+		encryptedPasteRef.Secret = secret
+	}
+	return encryptedPasteRef, nil
+}
+
+// CreateEncryptedPaste encrypts the code of the request with AES-256-GCM and creates a paste containing
+// only the resulting armored envelope, which means Pastebin never sees the plaintext.
+//
+// If passphrase is empty, a random key is generated and returned as part of the EncryptedPasteRef.
+// Otherwise, the key is derived from the passphrase using PBKDF2-HMAC-SHA256, and the passphrase must be provided
+// to GetEncryptedPasteContent.
+//
+// Note that the title of the paste is not encrypted.
+func (c *Client) CreateEncryptedPaste(request *CreatePasteRequest, passphrase string) (*EncryptedPasteRef, error) {
+	if len(request.Code) == 0 {
+		return nil, ErrEncryptedPasteRequiresContent
+	}
+	// The envelope would look like a high entropy string to the scanner, so the plaintext is scanned instead
+	plaintext, err := c.scanContent(request.Code)
+	if err != nil {
+		return nil, err
+	}
+	envelope, secret, err := encryptEnvelope([]byte(plaintext), passphrase)
+	if err != nil {
+		return nil, err
+	}
+	encryptedRequest := *request
+	encryptedRequest.Code = envelope
+	encryptedRequest.Syntax = "text"
+	result, err := c.createPaste(&encryptedRequest, false)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedPasteRef{Key: result.Key, Secret: secret}, nil
+}
+
+// GetEncryptedPasteContent retrieves and decrypts a paste created with CreateEncryptedPaste
+//
+// The ref parameter is the string returned by EncryptedPasteRef.String. If the paste was encrypted with a passphrase,
+// ref may simply be the paste key and the passphrase must be provided.
+func (c *Client) GetEncryptedPasteContent(ref, passphrase string) (string, error) {
+	encryptedPasteRef, err := ParseEncryptedPasteRef(ref)
+	if err != nil {
+		return "", err
+	}
+	envelope, err := c.fetchPasteContent(encryptedPasteRef.Key)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := decryptEnvelope(envelope, encryptedPasteRef.Secret, passphrase)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// encryptEnvelope encrypts the plaintext and returns the armored envelope as well as the generated secret,
+// if no passphrase was provided
+func encryptEnvelope(plaintext []byte, passphrase string) (string, []byte, error) {
+	headers := map[string]string{
+		"Version": strconv.Itoa(encryptedEnvelopeVersion),
+		"Cipher":  cipherAES256GCM,
+	}
+	var key, secret []byte
+	if len(passphrase) == 0 {
+		secret = make([]byte, encryptionKeySize)
+		if _, err := rand.Read(secret); err != nil {
+			return "", nil, err
+		}
+		key = secret
+		headers["KDF"] = kdfNone
+	} else {
+		salt := make([]byte, encryptionSaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return "", nil, err
+		}
+		key = pbkdf2SHA256([]byte(passphrase), salt, DefaultPassphraseIterations, encryptionKeySize)
+		headers["KDF"] = kdfPBKDF2SHA256
+		headers["Iterations"] = strconv.Itoa(DefaultPassphraseIterations)
+		headers["Salt"] = base64.StdEncoding.EncodeToString(salt)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	headerBlock := formatArmorHeaders(headers, []string{"Version", "Cipher", "KDF", "Iterations", "Salt"})
+	// The headers are authenticated, so that the KDF parameters cannot be tampered with
+	ciphertext := gcm.Seal(nonce, nonce, plaintext, []byte(headerBlock))
+	return armorPayload(encryptedEnvelopeHeader, encryptedEnvelopeFooter, headerBlock, base64.StdEncoding.EncodeToString(ciphertext)), secret, nil
+}
+
+// decryptEnvelope parses the armored envelope and decrypts it with either the secret or the passphrase
+func decryptEnvelope(envelope string, secret []byte, passphrase string) ([]byte, error) {
+	headerBlock, encodedCiphertext, ok := dearmor(encryptedEnvelopeHeader, encryptedEnvelopeFooter, envelope)
+	if !ok {
+		return nil, ErrInvalidEncryptedEnvelope
+	}
+	headers := parseArmorHeaders(headerBlock)
+	if headers["Version"] != strconv.Itoa(encryptedEnvelopeVersion) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEnvelopeVersion, headers["Version"])
+	}
+	if headers["Cipher"] != cipherAES256GCM {
+		return nil, fmt.Errorf("%w: unsupported cipher %s", ErrInvalidEncryptedEnvelope, headers["Cipher"])
+	}
+	var key []byte
+	switch headers["KDF"] {
+	case kdfNone:
+		if len(secret) == 0 {
+			return nil, ErrMissingDecryptionKey
+		}
+		key = secret
+	case kdfPBKDF2SHA256:
+		if len(passphrase) == 0 {
+			return nil, ErrMissingDecryptionKey
+		}
+		iterations, err := strconv.Atoi(headers["Iterations"])
+		if err != nil || iterations <= 0 || iterations > maxPassphraseIterations {
+			return nil, fmt.Errorf("%w: invalid iterations", ErrInvalidEncryptedEnvelope)
+		}
+		salt, err := base64.StdEncoding.DecodeString(headers["Salt"])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid salt", ErrInvalidEncryptedEnvelope)
+		}
+		key = pbkdf2SHA256([]byte(passphrase), salt, iterations, encryptionKeySize)
+	default:
+		return nil, fmt.Errorf("%w: unsupported KDF %s", ErrInvalidEncryptedEnvelope, headers["KDF"])
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encodedCiphertext)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidEncryptedEnvelope, err.Error())
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, ErrInvalidEncryptedEnvelope
+	}
+	plaintext, err := gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], []byte(headerBlock))
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 derives a key from the password and the salt as specified by RFC 8018, using HMAC-SHA256
+func pbkdf2SHA256(password, salt []byte, iterations, keyLength int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLength := prf.Size()
+	numberOfBlocks := (keyLength + hashLength - 1) / hashLength
+	var derivedKey []byte
+	blockIndex := make([]byte, 4)
+	for block := 1; block <= numberOfBlocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(blockIndex, uint32(block))
+		prf.Write(blockIndex)
+		u := prf.Sum(nil)
+		t := make([]byte, len(u))
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		derivedKey = append(derivedKey, t...)
+	}
+	return derivedKey[:keyLength]
+}
@@ -0,0 +1,127 @@
+package pastebin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+// newMockPastebin returns an HTTP client that stores created pastes in memory and serves them through the raw endpoint
+func newMockPastebin(pastes map[string]string) *http.Client {
+	return &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		if request.URL.String() == PostApiUrl {
+			body, _ := io.ReadAll(request.Body)
+			fields, _ := url.ParseQuery(string(body))
+			key := "key" + string(rune('a'+len(pastes)))
+			pastes[key] = fields.Get("api_paste_code")
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("https://pastebin.com/" + key))}
+		}
+		if content, exists := pastes[strings.TrimPrefix(request.URL.String(), RawUrlPrefix+"/")]; exists {
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString(content))}
+		}
+		return &http.Response{StatusCode: 404, Body: io.NopCloser(bytes.NewBufferString("Not Found"))}
+	})}
+}
+
+func TestClient_CreateEncryptedPasteWithRandomKey(t *testing.T) {
+	pastes := make(map[string]string)
+	httpClient = newMockPastebin(pastes)
+	client, _ := NewClient("", "", "token")
+	ref, err := client.CreateEncryptedPaste(NewCreatePasteRequest("title", "secret logs", ExpirationTenMinutes, VisibilityUnlisted, "go"), "")
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if len(ref.Secret) != encryptionKeySize {
+		t.Fatalf("expected a secret of %d bytes, got %d", encryptionKeySize, len(ref.Secret))
+	}
+	if strings.Contains(pastes[ref.Key], "secret logs") {
+		t.Error("the paste stored on Pastebin should not contain the plaintext")
+	}
+	if !strings.HasPrefix(pastes[ref.Key], encryptedEnvelopeHeader) {
+		t.Error("the paste stored on Pastebin should be an armored envelope")
+	}
+	content, err := client.GetEncryptedPasteContent(ref.String(), "")
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if content != "secret logs" {
+		t.Errorf("expected '%s', got '%s'", "secret logs", content)
+	}
+	if _, err = client.GetEncryptedPasteContent(ref.Key, ""); !errors.Is(err, ErrMissingDecryptionKey) {
+		t.Error("expected ErrMissingDecryptionKey, got", err)
+	}
+}
+
+func TestClient_CreateEncryptedPasteWithPassphrase(t *testing.T) {
+	pastes := make(map[string]string)
+	httpClient = newMockPastebin(pastes)
+	client, _ := NewClient("", "", "token")
+	ref, err := client.CreateEncryptedPaste(NewCreatePasteRequest("title", "secret logs", ExpirationTenMinutes, VisibilityUnlisted, ""), "correct horse")
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if ref.String() != ref.Key {
+		t.Error("a reference to a paste encrypted with a passphrase should not contain a secret")
+	}
+	if content, err := client.GetEncryptedPasteContent(ref.String(), "correct horse"); err != nil || content != "secret logs" {
+		t.Errorf("expected '%s', got '%s' with error %v", "secret logs", content, err)
+	}
+	if _, err = client.GetEncryptedPasteContent(ref.String(), "wrong horse"); !errors.Is(err, ErrDecryptionFailed) {
+		t.Error("expected ErrDecryptionFailed, got", err)
+	}
+}
+
+func TestDecryptEnvelopeWhenTampered(t *testing.T) {
+	envelope, secret, err := encryptEnvelope([]byte("content"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(envelope, "Cipher: aes-256-gcm", "Cipher: aes-256-gcm ", 1)
+	if _, err = decryptEnvelope(tampered, secret, ""); !errors.Is(err, ErrDecryptionFailed) {
+		t.Error("expected ErrDecryptionFailed, got", err)
+	}
+	if _, err = decryptEnvelope("not an envelope", secret, ""); !errors.Is(err, ErrInvalidEncryptedEnvelope) {
+		t.Error("expected ErrInvalidEncryptedEnvelope, got", err)
+	}
+}
+
+func TestDecryptEnvelopeWithTooManyIterations(t *testing.T) {
+	envelope, _, err := encryptEnvelope([]byte("content"), "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, iterations := range []string{"0", "-1", "6000001", "2147483647"} {
+		modified := strings.Replace(envelope, "Iterations: 600000", "Iterations: "+iterations, 1)
+		if _, err = decryptEnvelope(modified, nil, "correct horse"); !errors.Is(err, ErrInvalidEncryptedEnvelope) {
+			t.Errorf("expected ErrInvalidEncryptedEnvelope for %s iterations, got %v", iterations, err)
+		}
+	}
+}
+
+func TestParseEncryptedPasteRef(t *testing.T) {
+	if _, err := ParseEncryptedPasteRef("abcdefgh#not-a-valid-secret"); !errors.Is(err, ErrInvalidEncryptedPasteRef) {
+		t.Error("expected ErrInvalidEncryptedPasteRef, got", err)
+	}
+	if _, err := ParseEncryptedPasteRef(""); !errors.Is(err, ErrInvalidEncryptedPasteRef) {
+		t.Error("expected ErrInvalidEncryptedPasteRef, got", err)
+	}
+	ref, err := ParseEncryptedPasteRef("abcdefgh")
+	if err != nil || ref.Key != "abcdefgh" || ref.Secret != nil {
+		t.Errorf("expected reference without secret, got %#v with error %v", ref, err)
+	}
+}
+
+func TestPBKDF2SHA256(t *testing.T) {
+	// Test vector from RFC 7914, section 11
+	expected := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if derivedKey := hex.EncodeToString(pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1, 64)); derivedKey != expected {
+		t.Errorf("expected %s, got %s", expected, derivedKey)
+	}
+}
@@ -0,0 +1,26 @@
+package pastebin
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomically writes the data to a temporary file in the same directory as path, and then renames it to path,
+// so that concurrent readers never see a partially written file
+func writeFileAtomically(path string, data []byte) error {
+	temporaryFile, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = temporaryFile.Write(data)
+	if closeErr := temporaryFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temporaryFile.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(temporaryFile.Name())
+	}
+	return err
+}
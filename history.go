@@ -0,0 +1,233 @@
+package pastebin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const revisionHistoryVersion = 1
+
+var (
+	ErrRevisionNotFound = errors.New("revision not found")
+)
+
+// Revision is a single version of a document tracked by a RevisionHistory. Since pastes cannot be edited, every
+// revision of a document is a different paste.
+type Revision struct {
+	// Number is the number of the revision, starting at 1 for the first revision of the document
+	Number   int    `json:"number"`
+	PasteKey string `json:"paste_key"`
+
+	CreatedAt time.Time `json:"created_at"`
+
+	// SHA256 is the hex-encoded SHA-256 checksum of the content of the revision
+	SHA256 string `json:"sha256"`
+
+	// Author is the username of the client that created the revision, which is empty for guests
+	Author string `json:"author"`
+}
+
+// RevisionHistory records the revisions of named documents in a JSON file.
+//
+// A RevisionHistory is safe for concurrent use, but multiple processes recording revisions in the same file at the
+// same time may overwrite each other's changes.
+type RevisionHistory struct {
+	path  string
+	mutex sync.Mutex
+}
+
+type revisionHistoryFile struct {
+	Version   int                    `json:"version"`
+	Documents map[string][]*Revision `json:"documents"`
+}
+
+// NewRevisionHistory creates a RevisionHistory stored in a JSON file, which is created when the first revision is
+// recorded if it doesn't exist
+func NewRevisionHistory(path string) *RevisionHistory {
+	return &RevisionHistory{path: path}
+}
+
+// Record records a new revision of a document, creating the document if it doesn't exist
+func (h *RevisionHistory) Record(document, pasteKey, content, author string) (*Revision, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	file, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+	checksum := sha256.Sum256([]byte(content))
+	revision := &Revision{
+		Number:    len(file.Documents[document]) + 1,
+		PasteKey:  pasteKey,
+		CreatedAt: time.Now().UTC(),
+		SHA256:    hex.EncodeToString(checksum[:]),
+		Author:    author,
+	}
+	file.Documents[document] = append(file.Documents[document], revision)
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err = writeFileAtomically(h.path, data); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// Documents returns the name of every document with at least one revision, sorted alphabetically
+func (h *RevisionHistory) Documents() ([]string, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	file, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+	documents := make([]string, 0, len(file.Documents))
+	for document := range file.Documents {
+		documents = append(documents, document)
+	}
+	sort.Strings(documents)
+	return documents, nil
+}
+
+// Revisions returns every revision of a document, from the oldest to the most recent.
+// Returns ErrRevisionNotFound if the document has no revision.
+func (h *RevisionHistory) Revisions(document string) ([]*Revision, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	file, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+	revisions := file.Documents[document]
+	if len(revisions) == 0 {
+		return nil, fmt.Errorf("%w: document %s has no revision", ErrRevisionNotFound, document)
+	}
+	return revisions, nil
+}
+
+// Revision returns a revision of a document by number, or the most recent revision if number is 0
+func (h *RevisionHistory) Revision(document string, number int) (*Revision, error) {
+	revisions, err := h.Revisions(document)
+	if err != nil {
+		return nil, err
+	}
+	if number == 0 {
+		return revisions[len(revisions)-1], nil
+	}
+	if number < 0 || number > len(revisions) {
+		return nil, fmt.Errorf("%w: document %s has no revision %d", ErrRevisionNotFound, document, number)
+	}
+	return revisions[number-1], nil
+}
+
+// documentOf returns the document whose most recent revision is the paste, if any
+func (h *RevisionHistory) documentOf(pasteKey string) (string, bool, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	file, err := h.read()
+	if err != nil {
+		return "", false, err
+	}
+	for document, revisions := range file.Documents {
+		if len(revisions) > 0 && revisions[len(revisions)-1].PasteKey == pasteKey {
+			return document, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// read reads the history file, returning an empty history if it doesn't exist. Must be called with the mutex held.
+func (h *RevisionHistory) read() (*revisionHistoryFile, error) {
+	file := &revisionHistoryFile{Version: revisionHistoryVersion, Documents: make(map[string][]*Revision)}
+	data, err := os.ReadFile(h.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("invalid revision history %s: %w", h.path, err)
+	}
+	if file.Version != revisionHistoryVersion {
+		return nil, fmt.Errorf("unsupported revision history version %d", file.Version)
+	}
+	if file.Documents == nil {
+		file.Documents = make(map[string][]*Revision)
+	}
+	return file, nil
+}
+
+// WithRevisionHistory configures the client to record the revisions created with CreateRevision in the history, as
+// well as the pastes created by UpdatePaste when the paste being updated is the most recent revision of a document.
+// Passing nil removes the history.
+//
+// Returns the client to allow chaining
+func (c *Client) WithRevisionHistory(history *RevisionHistory) *Client {
+	c.history = history
+	return c
+}
+
+// CreateRevision creates a paste and records it as the new revision of a document.
+// Unlike UpdatePaste, the paste of the previous revision is kept, so that every revision can still be retrieved.
+// Requires a RevisionHistory to be configured with WithRevisionHistory.
+func (c *Client) CreateRevision(document string, request *CreatePasteRequest) (*Revision, error) {
+	if c.history == nil {
+		return nil, errors.New("no revision history configured, see WithRevisionHistory")
+	}
+	result, err := c.CreatePasteWithResult(request)
+	if err != nil {
+		return nil, err
+	}
+	// The content that was sent is what gets hashed, since it may have been redacted by the scanner
+	return c.history.Record(document, result.Key, result.content, c.username)
+}
+
+// GetRevisionContent retrieves the content of a revision of a document, or of its most recent revision if number is
+// 0, which fails if the paste of the revision was deleted or expired.
+// Requires a RevisionHistory to be configured with WithRevisionHistory.
+func (c *Client) GetRevisionContent(document string, number int) (string, error) {
+	if c.history == nil {
+		return "", errors.New("no revision history configured, see WithRevisionHistory")
+	}
+	revision, err := c.history.Revision(document, number)
+	if err != nil {
+		return "", err
+	}
+	content, err := c.fetchPasteContent(revision.PasteKey)
+	if err != nil {
+		return "", fmt.Errorf("revision %d of %s (paste %s) is no longer available: %w", revision.Number, document, revision.PasteKey, err)
+	}
+	return content, nil
+}
+
+// DiffRevisions returns the differences between two revisions of a document in the unified format, with
+// DefaultDiffContext unchanged lines around each change.
+// Requires a RevisionHistory to be configured with WithRevisionHistory.
+func (c *Client) DiffRevisions(document string, fromNumber, toNumber int) (string, error) {
+	fromContent, err := c.GetRevisionContent(document, fromNumber)
+	if err != nil {
+		return "", err
+	}
+	toContent, err := c.GetRevisionContent(document, toNumber)
+	if err != nil {
+		return "", err
+	}
+	// The revisions are resolved again to name them in the output, since 0 refers to the most recent revision
+	fromRevision, _ := c.history.Revision(document, fromNumber)
+	toRevision, _ := c.history.Revision(document, toNumber)
+	return UnifiedDiff(
+		fmt.Sprintf("%s@%d (%s)", document, fromRevision.Number, fromRevision.PasteKey),
+		fmt.Sprintf("%s@%d (%s)", document, toRevision.Number, toRevision.PasteKey),
+		fromContent, toContent, DefaultDiffContext,
+	), nil
+}
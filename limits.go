@@ -0,0 +1,92 @@
+package pastebin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Operation identifies a type of interaction with Pastebin's API
+type Operation string
+
+const (
+	OperationLogin                           Operation = "login"
+	OperationCreatePaste                     Operation = "create_paste"
+	OperationDeletePaste                     Operation = "delete_paste"
+	OperationGetAllUserPastes                Operation = "get_all_user_pastes"
+	OperationGetUserPasteContent             Operation = "get_user_paste_content"
+	OperationGetPasteContent                 Operation = "get_paste_content"
+	OperationGetPasteContentUsingScrapingAPI Operation = "get_paste_content_using_scraping_api"
+	OperationGetPasteUsingScrapingAPI        Operation = "get_paste_using_scraping_api"
+	OperationGetRecentPastesUsingScrapingAPI Operation = "get_recent_pastes_using_scraping_api"
+	OperationCheckPaste                      Operation = "check_paste"
+)
+
+// DefaultMaxResponseSize is the maximum number of bytes that will be read from a response body when no maximum
+// response size has been configured for the operation through Client.WithMaxResponseSize
+//
+// Pastebin PRO pastes can be up to 10MB, so this leaves some room for the overhead of the API.
+const DefaultMaxResponseSize int64 = 16 * 1024 * 1024
+
+var (
+	// ErrResponseTooLarge is returned when a response body exceeds the maximum response size of an operation.
+	// The error returned is always a *ResponseTooLargeError, which can be retrieved with errors.As.
+	ErrResponseTooLarge = errors.New("response body exceeds maximum size")
+)
+
+// ResponseTooLargeError is the error returned when a response body exceeds the maximum response size of an operation
+type ResponseTooLargeError struct {
+	Operation Operation
+
+	// MaxSize is the maximum number of bytes that was allowed for the operation
+	MaxSize int64
+
+	// BytesRead is the number of bytes that were read before the request was aborted
+	BytesRead int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("%s: read %d bytes for operation %s, but the maximum size is %d bytes", ErrResponseTooLarge.Error(), e.BytesRead, e.Operation, e.MaxSize)
+}
+
+// Is allows errors.Is(err, ErrResponseTooLarge) to match a *ResponseTooLargeError
+func (e *ResponseTooLargeError) Is(target error) bool {
+	return target == ErrResponseTooLarge
+}
+
+// WithMaxResponseSize sets the maximum number of bytes that may be read from the response body of a given operation.
+// A maxSize of 0 or less resets the maximum response size of the operation to DefaultMaxResponseSize.
+//
+// Returns the client to allow chaining
+func (c *Client) WithMaxResponseSize(operation Operation, maxSize int64) *Client {
+	if c.maxResponseSizes == nil {
+		c.maxResponseSizes = make(map[Operation]int64)
+	}
+	if maxSize <= 0 {
+		delete(c.maxResponseSizes, operation)
+	} else {
+		c.maxResponseSizes[operation] = maxSize
+	}
+	return c
+}
+
+// maxResponseSize returns the maximum number of bytes that may be read from the response body of a given operation
+func (c *Client) maxResponseSize(operation Operation) int64 {
+	if maxSize, exists := c.maxResponseSizes[operation]; exists {
+		return maxSize
+	}
+	return DefaultMaxResponseSize
+}
+
+// readResponseBody reads the body until EOF, or returns a *ResponseTooLargeError if the body is larger than maxSize
+func readResponseBody(operation Operation, body io.Reader, maxSize int64) ([]byte, error) {
+	// Read one extra byte, so we can tell the difference between a body of exactly maxSize bytes and a larger one
+	data, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, &ResponseTooLargeError{Operation: operation, MaxSize: maxSize, BytesRead: int64(len(data))}
+	}
+	return data, nil
+}
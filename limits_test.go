@@ -0,0 +1,82 @@
+package pastebin
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func TestClient_WithMaxResponseSize(t *testing.T) {
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString("0123456789")),
+		}
+	})}
+	client, _ := NewClient("", "", "token")
+	client.WithMaxResponseSize(OperationGetPasteContent, 5)
+	_, err := client.GetPasteContent("abcdefgh")
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatal("expected ErrResponseTooLarge, got", err)
+	}
+	var tooLargeErr *ResponseTooLargeError
+	if !errors.As(err, &tooLargeErr) {
+		t.Fatal("expected error to be a *ResponseTooLargeError")
+	}
+	if tooLargeErr.BytesRead != 6 {
+		t.Errorf("expected %d bytes to have been read, got %d", 6, tooLargeErr.BytesRead)
+	}
+	if tooLargeErr.Operation != OperationGetPasteContent {
+		t.Errorf("expected operation %s, got %s", OperationGetPasteContent, tooLargeErr.Operation)
+	}
+	// Other operations should not be affected
+	if _, err = client.GetPasteContentUsingScrapingAPI("abcdefgh"); err != nil {
+		t.Error("shouldn't have returned an error, got", err)
+	}
+	// Resetting the limit should fall back to DefaultMaxResponseSize
+	client.WithMaxResponseSize(OperationGetPasteContent, 0)
+	if content, err := client.GetPasteContent("abcdefgh"); err != nil || content != "0123456789" {
+		t.Errorf("expected '%s', got '%s' with error %v", "0123456789", content, err)
+	}
+}
+
+func TestReadResponseBody(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		body        string
+		maxSize     int64
+		expectedErr bool
+	}{
+		{
+			desc:    "body smaller than max size",
+			body:    "abc",
+			maxSize: 5,
+		},
+		{
+			desc:    "body exactly max size",
+			body:    "abcde",
+			maxSize: 5,
+		},
+		{
+			desc:        "body larger than max size",
+			body:        "abcdef",
+			maxSize:     5,
+			expectedErr: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			data, err := readResponseBody(OperationGetPasteContent, bytes.NewBufferString(tC.body), tC.maxSize)
+			if tC.expectedErr != (err != nil) {
+				t.Fatalf("expected error=%v, got %v", tC.expectedErr, err)
+			}
+			if !tC.expectedErr && string(data) != tC.body {
+				t.Errorf("expected '%s', got '%s'", tC.body, string(data))
+			}
+		})
+	}
+}
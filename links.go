@@ -0,0 +1,243 @@
+package pastebin
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DefaultBaseURL is the base URL used to build and parse links to pastes when none was configured with WithBaseURL
+const DefaultBaseURL = "https://pastebin.com"
+
+var (
+	ErrInvalidPasteKey = errors.New("invalid paste key")
+	ErrInvalidPasteRef = errors.New("invalid paste reference")
+)
+
+// LinkKind is the form of a link to a paste
+type LinkKind int
+
+const (
+	// LinkKindKey is a bare paste key (e.g. abcdefgh)
+	LinkKindKey LinkKind = iota
+
+	// LinkKindView is the canonical link to a paste (e.g. https://pastebin.com/abcdefgh)
+	LinkKindView
+
+	// LinkKindRaw is a link to the raw content of a paste (e.g. https://pastebin.com/raw/abcdefgh)
+	LinkKindRaw
+
+	// LinkKindDownload is a link to download a paste as a file (e.g. https://pastebin.com/dl/abcdefgh)
+	LinkKindDownload
+
+	// LinkKindEmbed is a link to embed a paste (e.g. https://pastebin.com/embed_iframe/abcdefgh)
+	LinkKindEmbed
+)
+
+func (k LinkKind) String() string {
+	switch k {
+	case LinkKindKey:
+		return "key"
+	case LinkKindView:
+		return "view"
+	case LinkKindRaw:
+		return "raw"
+	case LinkKindDownload:
+		return "download"
+	case LinkKindEmbed:
+		return "embed"
+	default:
+		return "unknown"
+	}
+}
+
+// linkKindsByPathPrefix maps the first segment of the path of a link to the kind of link
+var linkKindsByPathPrefix = map[string]LinkKind{
+	"raw":          LinkKindRaw,
+	"dl":           LinkKindDownload,
+	"embed":        LinkKindEmbed,
+	"embed_iframe": LinkKindEmbed,
+	"embed_js":     LinkKindEmbed,
+}
+
+// PasteRef is a reference to a paste, as parsed by ParsePasteRef
+type PasteRef struct {
+	// Key is the validated key of the paste
+	Key string
+
+	// Kind is the form of the link the reference was parsed from
+	Kind LinkKind
+}
+
+// maxPasteKeyLength is the maximum length of a paste key accepted by ValidatePasteKey
+const maxPasteKeyLength = 16
+
+// ValidatePasteKey returns an error matching ErrInvalidPasteKey if the key is empty, longer than 16 characters or
+// contains anything other than ASCII letters and digits.
+//
+// Pastebin currently generates keys of 8 characters, but the length is not enforced so that hosts configured with
+// WithBaseURL may use a different format.
+func ValidatePasteKey(key string) error {
+	if len(key) == 0 || len(key) > maxPasteKeyLength {
+		return fmt.Errorf("%w: %q must be between 1 and %d characters long", ErrInvalidPasteKey, key, maxPasteKeyLength)
+	}
+	for _, character := range key {
+		if (character < 'a' || character > 'z') && (character < 'A' || character > 'Z') && (character < '0' || character > '9') {
+			return fmt.Errorf("%w: %q must only contain letters and digits", ErrInvalidPasteKey, key)
+		}
+	}
+	return nil
+}
+
+// URLBuilder builds and parses links to pastes for a given base URL
+type URLBuilder struct {
+	baseURL *url.URL
+}
+
+// NewURLBuilder creates a new URLBuilder for the given base URL (e.g. https://pastebin.com)
+func NewURLBuilder(baseURL string) (*URLBuilder, error) {
+	parsedBaseURL, err := url.Parse(strings.TrimSuffix(strings.TrimSpace(baseURL), "/"))
+	if err != nil {
+		return nil, err
+	}
+	if (parsedBaseURL.Scheme != "http" && parsedBaseURL.Scheme != "https") || len(parsedBaseURL.Host) == 0 {
+		return nil, fmt.Errorf("invalid base URL %q: must be an absolute http or https URL", baseURL)
+	}
+	return &URLBuilder{baseURL: parsedBaseURL}, nil
+}
+
+var defaultURLBuilder, _ = NewURLBuilder(DefaultBaseURL)
+
+// BaseURL returns the base URL of the builder
+func (b *URLBuilder) BaseURL() string {
+	return b.baseURL.String()
+}
+
+// URL returns the link of the given kind to the paste with the given key.
+// For LinkKindKey, the key itself is returned.
+func (b *URLBuilder) URL(kind LinkKind, key string) string {
+	switch kind {
+	case LinkKindKey:
+		return key
+	case LinkKindRaw:
+		return b.RawURL(key)
+	case LinkKindDownload:
+		return b.DownloadURL(key)
+	case LinkKindEmbed:
+		return b.EmbedURL(key)
+	default:
+		return b.ViewURL(key)
+	}
+}
+
+// ViewURL returns the canonical link to the paste
+func (b *URLBuilder) ViewURL(key string) string {
+	return b.BaseURL() + "/" + key
+}
+
+// RawURL returns the link to the raw content of the paste
+func (b *URLBuilder) RawURL(key string) string {
+	return b.BaseURL() + "/raw/" + key
+}
+
+// DownloadURL returns the link to download the paste as a file
+func (b *URLBuilder) DownloadURL(key string) string {
+	return b.BaseURL() + "/dl/" + key
+}
+
+// EmbedURL returns the link to embed the paste in an iframe
+func (b *URLBuilder) EmbedURL(key string) string {
+	return b.BaseURL() + "/embed_iframe/" + key
+}
+
+// ParsePasteRef parses a paste key or a link to a paste whose host is the host of the base URL of the builder.
+//
+// The scheme and host are optional, and the query and fragment of the link are ignored.
+func (b *URLBuilder) ParsePasteRef(ref string) (*PasteRef, error) {
+	ref = strings.TrimSpace(ref)
+	if !strings.ContainsAny(ref, "/.") {
+		if err := ValidatePasteKey(ref); err != nil {
+			return nil, err
+		}
+		return &PasteRef{Key: ref, Kind: LinkKindKey}, nil
+	}
+	switch {
+	case strings.HasPrefix(ref, "/"):
+		ref = b.BaseURL() + ref
+	case !strings.Contains(ref, "://"):
+		ref = b.baseURL.Scheme + "://" + ref
+	}
+	parsedRef, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPasteRef, err.Error())
+	}
+	if !b.isSameHost(parsedRef.Hostname()) {
+		return nil, fmt.Errorf("%w: unexpected host %q", ErrInvalidPasteRef, parsedRef.Hostname())
+	}
+	path := strings.TrimPrefix(parsedRef.Path, b.baseURL.Path)
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	kind := LinkKindView
+	switch {
+	case len(segments) == 2:
+		var exists bool
+		if kind, exists = linkKindsByPathPrefix[segments[0]]; !exists {
+			return nil, fmt.Errorf("%w: unexpected path %q", ErrInvalidPasteRef, parsedRef.Path)
+		}
+	case len(segments) != 1:
+		return nil, fmt.Errorf("%w: unexpected path %q", ErrInvalidPasteRef, parsedRef.Path)
+	default:
+		if _, isPrefix := linkKindsByPathPrefix[segments[0]]; isPrefix {
+			return nil, fmt.Errorf("%w: missing paste key in path %q", ErrInvalidPasteRef, parsedRef.Path)
+		}
+	}
+	key := segments[len(segments)-1]
+	if err = ValidatePasteKey(key); err != nil {
+		return nil, err
+	}
+	return &PasteRef{Key: key, Kind: kind}, nil
+}
+
+// isSameHost returns whether the host is the host of the base URL, ignoring a "www." prefix on either side
+func (b *URLBuilder) isSameHost(host string) bool {
+	return strings.EqualFold(strings.TrimPrefix(host, "www."), strings.TrimPrefix(b.baseURL.Hostname(), "www."))
+}
+
+// ParsePasteRef parses a paste key or a link to a paste on pastebin.com, such as:
+//
+//	abcdefgh
+//	https://pastebin.com/abcdefgh
+//	pastebin.com/raw/abcdefgh
+//	/raw/abcdefgh
+//	https://pastebin.com/dl/abcdefgh?utm_source=chat
+//	https://pastebin.com/embed_iframe/abcdefgh
+//
+// The key of the returned PasteRef can be passed to any function taking a paste key, such as GetPasteContent.
+// See Client.URLBuilder for parsing links using the base URL configured with WithBaseURL.
+func ParsePasteRef(ref string) (*PasteRef, error) {
+	return defaultURLBuilder.ParsePasteRef(ref)
+}
+
+// WithBaseURL configures the base URL used to build the links returned by the client (e.g. by CreatePasteWithResult)
+// and to parse links with the URLBuilder returned by Client.URLBuilder. Defaults to DefaultBaseURL.
+//
+// Note that this does not change the URLs of Pastebin's API.
+//
+// Returns the client to allow chaining, or an error if the base URL is not an absolute http or https URL, in which
+// case the client is left unchanged
+func (c *Client) WithBaseURL(baseURL string) (*Client, error) {
+	urlBuilder, err := NewURLBuilder(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	c.urlBuilder = urlBuilder
+	return c, nil
+}
+
+// URLBuilder returns the URLBuilder of the client, which uses the base URL configured with WithBaseURL
+func (c *Client) URLBuilder() *URLBuilder {
+	if c.urlBuilder == nil {
+		return defaultURLBuilder
+	}
+	return c.urlBuilder
+}
@@ -0,0 +1,123 @@
+package pastebin
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func TestParsePasteRef(t *testing.T) {
+	testCases := []struct {
+		ref          string
+		expectedKey  string
+		expectedKind LinkKind
+	}{
+		{ref: "abc123", expectedKey: "abc123", expectedKind: LinkKindKey},
+		{ref: " abcdefgh\n", expectedKey: "abcdefgh", expectedKind: LinkKindKey},
+		{ref: "https://pastebin.com/abc123", expectedKey: "abc123", expectedKind: LinkKindView},
+		{ref: "http://www.pastebin.com/abc123/", expectedKey: "abc123", expectedKind: LinkKindView},
+		{ref: "pastebin.com/abc123", expectedKey: "abc123", expectedKind: LinkKindView},
+		{ref: "https://pastebin.com/raw/abc123", expectedKey: "abc123", expectedKind: LinkKindRaw},
+		{ref: "/raw/abc123", expectedKey: "abc123", expectedKind: LinkKindRaw},
+		{ref: "https://pastebin.com/dl/abc123?utm_source=chat", expectedKey: "abc123", expectedKind: LinkKindDownload},
+		{ref: "https://pastebin.com/embed/abc123", expectedKey: "abc123", expectedKind: LinkKindEmbed},
+		{ref: "https://pastebin.com/embed_iframe/abc123#top", expectedKey: "abc123", expectedKind: LinkKindEmbed},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.ref, func(t *testing.T) {
+			ref, err := ParsePasteRef(tC.ref)
+			if err != nil {
+				t.Fatal("shouldn't have returned an error, got", err)
+			}
+			if ref.Key != tC.expectedKey || ref.Kind != tC.expectedKind {
+				t.Errorf("expected key=%s kind=%s, got key=%s kind=%s", tC.expectedKey, tC.expectedKind, ref.Key, ref.Kind)
+			}
+		})
+	}
+}
+
+func TestParsePasteRefWithInvalidRef(t *testing.T) {
+	testCases := []struct {
+		ref         string
+		expectedErr error
+	}{
+		{ref: "", expectedErr: ErrInvalidPasteKey},
+		{ref: "abc-123", expectedErr: ErrInvalidPasteKey},
+		{ref: "https://pastebin.com/abcdefghijklmnopq", expectedErr: ErrInvalidPasteKey},
+		{ref: "https://pastebin.com/raw/", expectedErr: ErrInvalidPasteRef},
+		{ref: "https://pastebin.com/", expectedErr: ErrInvalidPasteKey},
+		{ref: "https://example.com/abc123", expectedErr: ErrInvalidPasteRef},
+		{ref: "https://pastebin.com/u/abc123", expectedErr: ErrInvalidPasteRef},
+		{ref: "https://pastebin.com/raw/abc123/extra", expectedErr: ErrInvalidPasteRef},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.ref, func(t *testing.T) {
+			if _, err := ParsePasteRef(tC.ref); !errors.Is(err, tC.expectedErr) {
+				t.Errorf("expected %v, got %v", tC.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestURLBuilder(t *testing.T) {
+	builder, err := NewURLBuilder("https://paste.example.com/pastebin/")
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	expectedURLs := map[LinkKind]string{
+		LinkKindKey:      "abc123",
+		LinkKindView:     "https://paste.example.com/pastebin/abc123",
+		LinkKindRaw:      "https://paste.example.com/pastebin/raw/abc123",
+		LinkKindDownload: "https://paste.example.com/pastebin/dl/abc123",
+		LinkKindEmbed:    "https://paste.example.com/pastebin/embed_iframe/abc123",
+	}
+	for kind, expectedURL := range expectedURLs {
+		url := builder.URL(kind, "abc123")
+		if url != expectedURL {
+			t.Errorf("expected %s, got %s", expectedURL, url)
+		}
+		ref, err := builder.ParsePasteRef(url)
+		if err != nil {
+			t.Fatal("shouldn't have returned an error, got", err)
+		}
+		if ref.Key != "abc123" || ref.Kind != kind {
+			t.Errorf("expected key=abc123 kind=%s, got key=%s kind=%s", kind, ref.Key, ref.Kind)
+		}
+	}
+	if _, err = builder.ParsePasteRef("https://pastebin.com/abc123"); !errors.Is(err, ErrInvalidPasteRef) {
+		t.Errorf("expected %v, got %v", ErrInvalidPasteRef, err)
+	}
+	if _, err = NewURLBuilder("pastebin.com"); err == nil {
+		t.Error("should've returned an error for a base URL without scheme")
+	}
+}
+
+func TestClient_WithBaseURL(t *testing.T) {
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("https://pastebin.com/abcdefgh"))}
+	})}
+	client, _ := NewClient("", "", "token")
+	if client.URLBuilder().BaseURL() != DefaultBaseURL {
+		t.Errorf("expected %s, got %s", DefaultBaseURL, client.URLBuilder().BaseURL())
+	}
+	if _, err := client.WithBaseURL("paste.example.com"); err == nil {
+		t.Error("should've returned an error for a base URL without scheme")
+	}
+	if client.URLBuilder().BaseURL() != DefaultBaseURL {
+		t.Error("the base URL shouldn't have changed after an invalid base URL")
+	}
+	if _, err := client.WithBaseURL("https://paste.example.com"); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	result, err := client.CreatePasteWithResult(NewCreatePasteRequest("", "content", ExpirationNever, VisibilityPublic, ""))
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if result.Key != "abcdefgh" || result.URL != "https://paste.example.com/abcdefgh" || result.RawURL != "https://paste.example.com/raw/abcdefgh" {
+		t.Errorf("expected the links to use the configured base URL, got %+v", result)
+	}
+}
@@ -0,0 +1,110 @@
+package pastebin
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// apiOptions maps the operations that use Pastebin's API to the value of their api_option field
+var apiOptions = map[Operation]string{
+	OperationCreatePaste:         "paste",
+	OperationDeletePaste:         "delete",
+	OperationGetAllUserPastes:    "list",
+	OperationGetUserPasteContent: "show_paste",
+}
+
+// WithLogger configures the client to emit structured logs for every interaction with Pastebin.
+//
+// Every request is logged at the debug level with its endpoint, api_option, status, duration and paste key, while
+// re-authentications and rate limit waits are logged at the warn level. The developer API key, the password,
+// the session key and the content of pastes are never logged.
+//
+// Passing nil disables logging.
+//
+// Returns the client to allow chaining
+func (c *Client) WithLogger(logger *slog.Logger) *Client {
+	if logger == nil {
+		c.logger = nil
+	} else {
+		c.logger = &loggingObserver{client: c, logger: logger}
+	}
+	return c
+}
+
+// loggingObserver is the Observer that writes the logs of a Client configured with WithLogger
+type loggingObserver struct {
+	client *Client
+	logger *slog.Logger
+}
+
+func (lo *loggingObserver) OnRequestStart(*RequestStartEvent) {}
+
+func (lo *loggingObserver) OnRequestFinish(event *RequestFinishEvent) {
+	if !lo.logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	attributes := []slog.Attr{
+		slog.String("operation", string(event.Operation)),
+		slog.String("endpoint", endpoint(event.URL)),
+		slog.Int("status", event.StatusCode),
+		slog.Duration("duration", event.Duration),
+		slog.Int64("bytes", event.BytesRead),
+	}
+	if apiOption, exists := apiOptions[event.Operation]; exists {
+		attributes = append(attributes, slog.String("api_option", apiOption))
+	}
+	if len(event.PasteKey) > 0 {
+		attributes = append(attributes, slog.String("paste_key", event.PasteKey))
+	}
+	if event.Err != nil {
+		attributes = append(attributes, slog.String("error", lo.client.redact(event.Err.Error())))
+	}
+	lo.logger.LogAttrs(context.Background(), slog.LevelDebug, "pastebin request", attributes...)
+}
+
+func (lo *loggingObserver) OnRetry(event *RetryEvent) {
+	lo.logger.LogAttrs(context.Background(), slog.LevelDebug, "retrying pastebin request",
+		slog.String("operation", string(event.Operation)),
+		slog.Int("attempt", event.Attempt),
+		slog.String("reason", event.Reason),
+	)
+}
+
+func (lo *loggingObserver) OnReLogin(event *ReLoginEvent) {
+	attributes := []slog.Attr{slog.String("operation", string(event.Operation))}
+	if event.Err != nil {
+		attributes = append(attributes, slog.String("error", lo.client.redact(event.Err.Error())))
+	}
+	lo.logger.LogAttrs(context.Background(), slog.LevelWarn, "re-authenticated with pastebin due to invalid session key", attributes...)
+}
+
+func (lo *loggingObserver) OnRateLimitWait(event *RateLimitWaitEvent) {
+	lo.logger.LogAttrs(context.Background(), slog.LevelWarn, "waiting for pastebin rate limit",
+		slog.String("operation", string(event.Operation)),
+		slog.Duration("wait", event.Wait),
+	)
+}
+
+// redact replaces every credential of the client found in s
+func (c *Client) redact(s string) string {
+	for _, secret := range []string{c.developerApiKey, c.password, c.getSessionKey()} {
+		if len(secret) > 0 {
+			s = strings.ReplaceAll(s, secret, redacted)
+		}
+	}
+	return s
+}
+
+// endpoint returns the URL without its query, which may contain a paste key
+func endpoint(rawUrl string) string {
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return redacted
+	}
+	parsedUrl.RawQuery = ""
+	return parsedUrl.String()
+}
@@ -0,0 +1,50 @@
+package pastebin
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func TestClient_WithLogger(t *testing.T) {
+	numberOfCallsToLoginApiUrl := 0
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		if request.URL.String() == LoginApiUrl {
+			numberOfCallsToLoginApiUrl++
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("secret-session-key"))}
+		}
+		if numberOfCallsToLoginApiUrl == 1 {
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("Bad API request, invalid api_user_key"))}
+		}
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("https://pastebin.com/abcdefgh"))}
+	})}
+	var output bytes.Buffer
+	client, _ := NewClient("username", "secret-password", "secret-token")
+	client.WithLogger(slog.New(slog.NewTextHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	if _, err := client.CreatePaste(NewCreatePasteRequest("title", "secret-content", ExpirationTenMinutes, VisibilityPublic, "")); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	logs := output.String()
+	for _, secret := range []string{"secret-session-key", "secret-password", "secret-token", "secret-content"} {
+		if strings.Contains(logs, secret) {
+			t.Errorf("logs should not contain %s, got:\n%s", secret, logs)
+		}
+	}
+	for _, expected := range []string{"level=DEBUG msg=\"pastebin request\"", "api_option=paste", "status=200", "level=WARN msg=\"re-authenticated with pastebin due to invalid session key\""} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("logs should contain %s, got:\n%s", expected, logs)
+		}
+	}
+}
+
+func TestClient_Redact(t *testing.T) {
+	client := &Client{developerApiKey: "token", password: "password", sessionKey: "session-key"}
+	if redactedString := client.redact("token password session-key"); redactedString != "[REDACTED] [REDACTED] [REDACTED]" {
+		t.Errorf("expected all credentials to be redacted, got %s", redactedString)
+	}
+}
@@ -0,0 +1,248 @@
+package pastebin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the buckets of the request latency histogram
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Error classes used by Metrics to label errors
+const (
+	ErrorClassTimeout          = "timeout"
+	ErrorClassNetwork          = "network"
+	ErrorClassResponseTooLarge = "response_too_large"
+	ErrorClassHTTPStatus       = "http_status"
+	ErrorClassAPI              = "api"
+)
+
+// RecentPastesObserver is an optional interface that an Observer can implement to be notified of the pastes
+// returned by GetRecentPastesUsingScrapingAPI
+type RecentPastesObserver interface {
+	OnRecentPastes(pastes []*Paste)
+}
+
+// Metrics is an Observer that collects metrics about the requests of the clients it observes, and serves them
+// in the Prometheus text exposition format as an http.Handler.
+//
+// A single Metrics can observe multiple clients.
+type Metrics struct {
+	buckets []float64
+
+	requests            map[Operation]uint64
+	errors              map[metricsErrorKey]uint64
+	latencies           map[Operation]*histogram
+	bytesReceived       map[Operation]uint64
+	bytesSent           map[Operation]uint64
+	reLogins            uint64
+	rateLimitWaits      uint64
+	rateLimitWaitTime   time.Duration
+	scrapingFeedLag     time.Duration
+	scrapingFeedUpdated bool
+
+	mutex sync.Mutex
+}
+
+type metricsErrorKey struct {
+	operation Operation
+	class     string
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewMetrics creates a new Metrics using DefaultLatencyBuckets.
+// To collect metrics, the Metrics must be added to a client with Client.WithObserver.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		buckets:       DefaultLatencyBuckets,
+		requests:      make(map[Operation]uint64),
+		errors:        make(map[metricsErrorKey]uint64),
+		latencies:     make(map[Operation]*histogram),
+		bytesReceived: make(map[Operation]uint64),
+		bytesSent:     make(map[Operation]uint64),
+	}
+}
+
+func (m *Metrics) OnRequestStart(*RequestStartEvent) {}
+
+func (m *Metrics) OnRequestFinish(event *RequestFinishEvent) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.requests[event.Operation]++
+	m.bytesReceived[event.Operation] += uint64(event.BytesRead)
+	m.bytesSent[event.Operation] += uint64(event.BytesSent)
+	latency, exists := m.latencies[event.Operation]
+	if !exists {
+		latency = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latencies[event.Operation] = latency
+	}
+	seconds := event.Duration.Seconds()
+	for i, upperBound := range m.buckets {
+		if seconds <= upperBound {
+			latency.counts[i]++
+		}
+	}
+	latency.count++
+	latency.sum += seconds
+	if class := classifyError(event); len(class) > 0 {
+		m.errors[metricsErrorKey{operation: event.Operation, class: class}]++
+	}
+}
+
+func (m *Metrics) OnRetry(*RetryEvent) {}
+
+func (m *Metrics) OnReLogin(*ReLoginEvent) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.reLogins++
+}
+
+func (m *Metrics) OnRateLimitWait(event *RateLimitWaitEvent) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.rateLimitWaits++
+	m.rateLimitWaitTime += event.Wait
+}
+
+// OnRecentPastes updates the scraping feed lag, which is the time elapsed since the most recent paste was created
+func (m *Metrics) OnRecentPastes(pastes []*Paste) {
+	var mostRecent time.Time
+	for _, paste := range pastes {
+		if paste.Date.After(mostRecent) {
+			mostRecent = paste.Date
+		}
+	}
+	if mostRecent.IsZero() {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.scrapingFeedLag = time.Since(mostRecent)
+	m.scrapingFeedUpdated = true
+}
+
+// classifyError returns the class of the error of the event, or an empty string if the request succeeded
+func classifyError(event *RequestFinishEvent) string {
+	var netErr net.Error
+	switch {
+	case event.Err == nil && event.StatusCode != 0 && event.StatusCode != 200 && event.StatusCode != http.StatusNotModified:
+		return ErrorClassHTTPStatus
+	case event.Err == nil && len(event.APIError) > 0:
+		return ErrorClassAPI
+	case event.Err == nil:
+		return ""
+	case errors.Is(event.Err, ErrResponseTooLarge):
+		return ErrorClassResponseTooLarge
+	case errors.As(event.Err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	default:
+		return ErrorClassNetwork
+	}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (m *Metrics) ServeHTTP(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(writer)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format to w
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var output strings.Builder
+	writeHeader(&output, "pastebin_requests_total", "counter", "Total number of requests sent to Pastebin.")
+	for _, operation := range sortedOperations(m.requests) {
+		writeSample(&output, "pastebin_requests_total", labels("operation", string(operation)), strconv.FormatUint(m.requests[operation], 10))
+	}
+	writeHeader(&output, "pastebin_request_errors_total", "counter", "Total number of failed requests sent to Pastebin, by error class.")
+	errorKeys := make([]metricsErrorKey, 0, len(m.errors))
+	for key := range m.errors {
+		errorKeys = append(errorKeys, key)
+	}
+	sort.Slice(errorKeys, func(i, j int) bool {
+		if errorKeys[i].operation != errorKeys[j].operation {
+			return errorKeys[i].operation < errorKeys[j].operation
+		}
+		return errorKeys[i].class < errorKeys[j].class
+	})
+	for _, key := range errorKeys {
+		writeSample(&output, "pastebin_request_errors_total", labels("operation", string(key.operation), "class", key.class), strconv.FormatUint(m.errors[key], 10))
+	}
+	writeHeader(&output, "pastebin_request_duration_seconds", "histogram", "Latency of the requests sent to Pastebin.")
+	for _, operation := range sortedOperations(m.latencies) {
+		latency := m.latencies[operation]
+		for i, upperBound := range m.buckets {
+			writeSample(&output, "pastebin_request_duration_seconds_bucket", labels("operation", string(operation), "le", formatFloat(upperBound)), strconv.FormatUint(latency.counts[i], 10))
+		}
+		writeSample(&output, "pastebin_request_duration_seconds_bucket", labels("operation", string(operation), "le", "+Inf"), strconv.FormatUint(latency.count, 10))
+		writeSample(&output, "pastebin_request_duration_seconds_sum", labels("operation", string(operation)), formatFloat(latency.sum))
+		writeSample(&output, "pastebin_request_duration_seconds_count", labels("operation", string(operation)), strconv.FormatUint(latency.count, 10))
+	}
+	writeHeader(&output, "pastebin_response_bytes_total", "counter", "Total number of bytes received from Pastebin.")
+	for _, operation := range sortedOperations(m.bytesReceived) {
+		writeSample(&output, "pastebin_response_bytes_total", labels("operation", string(operation)), strconv.FormatUint(m.bytesReceived[operation], 10))
+	}
+	writeHeader(&output, "pastebin_request_bytes_total", "counter", "Total number of bytes sent to Pastebin.")
+	for _, operation := range sortedOperations(m.bytesSent) {
+		writeSample(&output, "pastebin_request_bytes_total", labels("operation", string(operation)), strconv.FormatUint(m.bytesSent[operation], 10))
+	}
+	writeHeader(&output, "pastebin_relogins_total", "counter", "Total number of re-authentications caused by an invalid session key.")
+	writeSample(&output, "pastebin_relogins_total", "", strconv.FormatUint(m.reLogins, 10))
+	writeHeader(&output, "pastebin_rate_limit_waits_total", "counter", "Total number of requests that had to wait because of the rate limit.")
+	writeSample(&output, "pastebin_rate_limit_waits_total", "", strconv.FormatUint(m.rateLimitWaits, 10))
+	writeHeader(&output, "pastebin_rate_limit_wait_seconds_total", "counter", "Total time spent waiting because of the rate limit.")
+	writeSample(&output, "pastebin_rate_limit_wait_seconds_total", "", formatFloat(m.rateLimitWaitTime.Seconds()))
+	if m.scrapingFeedUpdated {
+		writeHeader(&output, "pastebin_scraping_feed_lag_seconds", "gauge", "Time elapsed between the creation of the most recent paste and the moment it was retrieved from the scraping API.")
+		writeSample(&output, "pastebin_scraping_feed_lag_seconds", "", formatFloat(m.scrapingFeedLag.Seconds()))
+	}
+	n, err := io.WriteString(w, output.String())
+	return int64(n), err
+}
+
+func writeHeader(output *strings.Builder, name, metricType, help string) {
+	fmt.Fprintf(output, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func writeSample(output *strings.Builder, name, labels, value string) {
+	fmt.Fprintf(output, "%s%s %s\n", name, labels, value)
+}
+
+// labels formats pairs of label names and values, escaping the values as required by the exposition format
+func labels(namesAndValues ...string) string {
+	var pairs []string
+	for i := 0; i+1 < len(namesAndValues); i += 2 {
+		value := strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(namesAndValues[i+1])
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, namesAndValues[i], value))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func sortedOperations[V any](values map[Operation]V) []Operation {
+	operations := make([]Operation, 0, len(values))
+	for operation := range values {
+		operations = append(operations, operation)
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i] < operations[j]
+	})
+	return operations
+}
@@ -0,0 +1,59 @@
+package pastebin
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func TestMetrics(t *testing.T) {
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		if strings.HasSuffix(request.URL.Path, "/missing") {
+			return &http.Response{StatusCode: 404, Body: io.NopCloser(bytes.NewBufferString("Not Found"))}
+		}
+		if request.URL.String() == ScrapeItemApiUrl+"?i=invalid" {
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("Error, paste key is not valid."))}
+		}
+		if strings.HasPrefix(request.URL.String(), ScrapingApiUrl) {
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString(`[{"date":"` + strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10) + `"}]`))}
+		}
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("this is code"))}
+	})}
+	metrics := NewMetrics()
+	client, _ := NewClient("", "", "token")
+	client.WithObserver(metrics)
+	_, _ = client.GetPasteContent("abcdefgh")
+	_, _ = client.GetPasteContent("missing")
+	_, _ = client.GetPasteContentUsingScrapingAPI("invalid")
+	_, _ = client.GetRecentPastesUsingScrapingAPI("", 1)
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	output := recorder.Body.String()
+	for _, expected := range []string{
+		`pastebin_requests_total{operation="get_paste_content"} 2`,
+		`pastebin_request_errors_total{operation="get_paste_content",class="http_status"} 1`,
+		`pastebin_request_errors_total{operation="get_paste_content_using_scraping_api",class="api"} 1`,
+		`pastebin_request_duration_seconds_count{operation="get_paste_content"} 2`,
+		`pastebin_request_duration_seconds_bucket{operation="get_paste_content",le="+Inf"} 2`,
+		`pastebin_response_bytes_total{operation="get_paste_content"} 21`,
+		`pastebin_relogins_total 0`,
+		`# TYPE pastebin_scraping_feed_lag_seconds gauge`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %s, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestLabels(t *testing.T) {
+	if output := labels("operation", `a"b\c`); output != `{operation="a\"b\\c"}` {
+		t.Errorf("expected label value to be escaped, got %s", output)
+	}
+}
@@ -0,0 +1,238 @@
+package pastebin
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Observer is notified of what the client is doing, which allows wiring the client to a metrics or tracing stack
+//
+// Observers are called synchronously, so they should not block. If you only care about some events, you can use Hooks.
+type Observer interface {
+	// OnRequestStart is called right before a request is sent
+	OnRequestStart(event *RequestStartEvent)
+
+	// OnRequestFinish is called once the response body of a request has been read, or once the request has failed
+	OnRequestFinish(event *RequestFinishEvent)
+
+	// OnRetry is called when a request is about to be sent again
+	OnRetry(event *RetryEvent)
+
+	// OnReLogin is called after the client re-authenticated because the session key was no longer valid
+	OnReLogin(event *ReLoginEvent)
+
+	// OnRateLimitWait is called when a request has to wait because of the rate limit configured with WithRateLimit
+	OnRateLimitWait(event *RateLimitWaitEvent)
+}
+
+// RequestStartEvent is the event passed to Observer.OnRequestStart
+type RequestStartEvent struct {
+	Operation Operation
+	Method    string
+	URL       string
+
+	// PasteKey is the key of the paste targeted by the request, if any
+	PasteKey string
+
+	StartedAt time.Time
+}
+
+// RequestFinishEvent is the event passed to Observer.OnRequestFinish
+type RequestFinishEvent struct {
+	Operation Operation
+	Method    string
+	URL       string
+
+	// PasteKey is the key of the paste targeted by the request, if any
+	PasteKey string
+
+	// StatusCode is the HTTP status code of the response, or 0 if no response was received
+	StatusCode int
+
+	// BytesRead is the number of bytes read from the response body
+	BytesRead int64
+
+	// BytesSent is the number of bytes sent in the request body
+	BytesSent int64
+
+	// Duration is the time elapsed between the start of the request and the moment the response body was read
+	Duration time.Duration
+
+	// Timings are the durations of the different phases of the request
+	Timings RequestTimings
+
+	// Err is the error that caused the request to fail, if any.
+	// Note that Pastebin often reports errors with a 200 status code, in which case Err is nil and APIError is set.
+	Err error
+
+	// APIError is the error message returned by Pastebin with a 200 status code (e.g. "Bad API request, ..."), if any
+	APIError string
+}
+
+// RequestTimings are the durations of the different phases of a request, as reported by net/http/httptrace.
+// Phases that did not happen (e.g. DNS resolution and TLS handshake when a connection was reused) are 0.
+type RequestTimings struct {
+	DNSLookup    time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+
+	// TimeToFirstByte is the time elapsed between the start of the request and the first byte of the response
+	TimeToFirstByte time.Duration
+
+	// ReusedConnection is whether the request was sent over a connection that was already open
+	ReusedConnection bool
+}
+
+// RetryEvent is the event passed to Observer.OnRetry
+type RetryEvent struct {
+	Operation Operation
+
+	// Attempt is the number of the attempt that is about to be made, starting at 2 for the first retry
+	Attempt int
+
+	Reason string
+}
+
+// ReLoginEvent is the event passed to Observer.OnReLogin
+type ReLoginEvent struct {
+	// Operation is the operation that failed because of the invalid session key
+	Operation Operation
+
+	// Err is the error returned by the login, if it failed
+	Err error
+}
+
+// RateLimitWaitEvent is the event passed to Observer.OnRateLimitWait
+type RateLimitWaitEvent struct {
+	Operation Operation
+	Wait      time.Duration
+}
+
+// Hooks is an Observer that calls the functions it was configured with, ignoring the events with no function
+type Hooks struct {
+	RequestStart  func(event *RequestStartEvent)
+	RequestFinish func(event *RequestFinishEvent)
+	Retry         func(event *RetryEvent)
+	ReLogin       func(event *ReLoginEvent)
+	RateLimitWait func(event *RateLimitWaitEvent)
+}
+
+func (h *Hooks) OnRequestStart(event *RequestStartEvent) {
+	if h.RequestStart != nil {
+		h.RequestStart(event)
+	}
+}
+
+func (h *Hooks) OnRequestFinish(event *RequestFinishEvent) {
+	if h.RequestFinish != nil {
+		h.RequestFinish(event)
+	}
+}
+
+func (h *Hooks) OnRetry(event *RetryEvent) {
+	if h.Retry != nil {
+		h.Retry(event)
+	}
+}
+
+func (h *Hooks) OnReLogin(event *ReLoginEvent) {
+	if h.ReLogin != nil {
+		h.ReLogin(event)
+	}
+}
+
+func (h *Hooks) OnRateLimitWait(event *RateLimitWaitEvent) {
+	if h.RateLimitWait != nil {
+		h.RateLimitWait(event)
+	}
+}
+
+// WithObserver adds an observer to the client. Multiple observers can be added, in which case they are notified
+// in the order they were added.
+//
+// Observers must be added before the client is used concurrently.
+//
+// Returns the client to allow chaining
+func (c *Client) WithObserver(observer Observer) *Client {
+	c.observers = append(c.observers, observer)
+	return c
+}
+
+// notify calls fn for each observer of the client, including the one configured through WithLogger
+func (c *Client) notify(fn func(observer Observer)) {
+	for _, observer := range c.observers {
+		fn(observer)
+	}
+	if c.logger != nil {
+		fn(c.logger)
+	}
+}
+
+// isObserved returns whether the client has at least one observer to notify
+func (c *Client) isObserved() bool {
+	return len(c.observers) > 0 || c.logger != nil
+}
+
+// requestTrace collects the RequestTimings of a request
+type requestTrace struct {
+	startedAt time.Time
+	timings   RequestTimings
+	mutex     sync.Mutex
+
+	dnsStartedAt, connectStartedAt, tlsHandshakeStartedAt time.Time
+}
+
+// clientTrace returns the httptrace.ClientTrace that populates the timings of the requestTrace.
+// The callbacks may be called from other goroutines, hence the mutex.
+func (rt *requestTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			rt.mutex.Lock()
+			defer rt.mutex.Unlock()
+			rt.timings.ReusedConnection = info.Reused
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			rt.mutex.Lock()
+			defer rt.mutex.Unlock()
+			rt.dnsStartedAt = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			rt.mutex.Lock()
+			defer rt.mutex.Unlock()
+			rt.timings.DNSLookup = time.Since(rt.dnsStartedAt)
+		},
+		ConnectStart: func(string, string) {
+			rt.mutex.Lock()
+			defer rt.mutex.Unlock()
+			rt.connectStartedAt = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			rt.mutex.Lock()
+			defer rt.mutex.Unlock()
+			rt.timings.Connect = time.Since(rt.connectStartedAt)
+		},
+		TLSHandshakeStart: func() {
+			rt.mutex.Lock()
+			defer rt.mutex.Unlock()
+			rt.tlsHandshakeStartedAt = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			rt.mutex.Lock()
+			defer rt.mutex.Unlock()
+			rt.timings.TLSHandshake = time.Since(rt.tlsHandshakeStartedAt)
+		},
+		GotFirstResponseByte: func() {
+			rt.mutex.Lock()
+			defer rt.mutex.Unlock()
+			rt.timings.TimeToFirstByte = time.Since(rt.startedAt)
+		},
+	}
+}
+
+func (rt *requestTrace) getTimings() RequestTimings {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	return rt.timings
+}
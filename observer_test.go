@@ -0,0 +1,79 @@
+package pastebin
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func TestClient_WithObserver(t *testing.T) {
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("this is code"))}
+	})}
+	var startEvents []*RequestStartEvent
+	var finishEvents []*RequestFinishEvent
+	client, _ := NewClient("", "", "token")
+	client.WithObserver(&Hooks{
+		RequestStart: func(event *RequestStartEvent) {
+			startEvents = append(startEvents, event)
+		},
+		RequestFinish: func(event *RequestFinishEvent) {
+			finishEvents = append(finishEvents, event)
+		},
+	})
+	if _, err := client.GetPasteContent("abcdefgh"); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if len(startEvents) != 1 || len(finishEvents) != 1 {
+		t.Fatalf("expected 1 start event and 1 finish event, got %d and %d", len(startEvents), len(finishEvents))
+	}
+	if startEvents[0].Operation != OperationGetPasteContent || startEvents[0].PasteKey != "abcdefgh" {
+		t.Errorf("unexpected start event %+v", startEvents[0])
+	}
+	if finishEvents[0].StatusCode != 200 || finishEvents[0].BytesRead != int64(len("this is code")) || finishEvents[0].Err != nil {
+		t.Errorf("unexpected finish event %+v", finishEvents[0])
+	}
+}
+
+func TestClient_WithObserverWhenSessionKeyExpired(t *testing.T) {
+	numberOfCallsToLoginApiUrl := 0
+	var sessionKeysUsed []string
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		if request.URL.String() == LoginApiUrl {
+			numberOfCallsToLoginApiUrl++
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("session-key-" + string(rune('0'+numberOfCallsToLoginApiUrl))))}
+		}
+		_ = request.ParseForm()
+		sessionKeysUsed = append(sessionKeysUsed, request.PostForm.Get("api_user_key"))
+		if request.PostForm.Get("api_user_key") == "session-key-1" {
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("Bad API request, invalid api_user_key"))}
+		}
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("https://pastebin.com/abcdefgh"))}
+	})}
+	var reLoginEvents []*ReLoginEvent
+	var retryEvents []*RetryEvent
+	client, _ := NewClient("username", "password", "token")
+	client.WithObserver(&Hooks{
+		ReLogin: func(event *ReLoginEvent) {
+			reLoginEvents = append(reLoginEvents, event)
+		},
+		Retry: func(event *RetryEvent) {
+			retryEvents = append(retryEvents, event)
+		},
+	})
+	if _, err := client.CreatePaste(NewCreatePasteRequest("", "content", ExpirationTenMinutes, VisibilityPublic, "")); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if len(reLoginEvents) != 1 || reLoginEvents[0].Err != nil || reLoginEvents[0].Operation != OperationCreatePaste {
+		t.Errorf("expected 1 successful re-login event, got %v", reLoginEvents)
+	}
+	if len(retryEvents) != 1 || retryEvents[0].Attempt != 2 {
+		t.Errorf("expected 1 retry event, got %v", retryEvents)
+	}
+	if len(sessionKeysUsed) != 2 || sessionKeysUsed[1] != "session-key-2" {
+		t.Errorf("the retry should've used the new session key, got %v", sessionKeysUsed)
+	}
+}
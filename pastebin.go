@@ -6,11 +6,13 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -42,8 +44,19 @@ const (
 	RawUrlPrefix = "https://pastebin.com/raw"
 )
 
+// MaxUserPastes is the maximum number of pastes Pastebin returns when listing the pastes of a user, which is how many
+// pastes are listed by the functions that need every paste of the user, such as Backup and ApplyRetentionPolicy
+const MaxUserPastes = 1000
+
+// userPastesLimit is the number of pastes returned by GetAllUserPastes
+const userPastesLimit = 100
+
 var (
 	ErrNotAuthenticated = errors.New("must be authenticated to perform this action")
+
+	// ErrPasteListTruncated is returned when an operation needs every paste of the user, but the user has at least
+	// MaxUserPastes pastes, in which case Pastebin only returns the most recent ones
+	ErrPasteListTruncated = errors.New("the list of pastes may be truncated")
 )
 
 // Client is the Pastebin client for performing operations that require authentication
@@ -52,6 +65,22 @@ type Client struct {
 	password        string
 	developerApiKey string
 	sessionKey      string
+
+	// sessionKeyMutex protects sessionKey, which may be replaced by a re-authentication while other requests are in flight
+	sessionKeyMutex sync.RWMutex
+
+	maxResponseSizes map[Operation]int64
+	contentCache     *contentCache
+	requestGroup     *requestGroup
+	scanner          Scanner
+	policy           *Policy
+	urlBuilder       *URLBuilder
+	aliases          *AliasRegistry
+	history          *RevisionHistory
+	trash            *Trash
+	rateLimiter      *rateLimiter
+	observers        []Observer
+	logger           *loggingObserver
 }
 
 // NewClient creates a new Client and authenticates said client before returning if the username parameter is passed.
@@ -72,80 +101,155 @@ func NewClient(username, password, developerApiKey string) (*Client, error) {
 // CreatePaste creates a new paste and returns the paste key
 // If the client was only provided with a developer API key, a guest paste will be created.
 // You can get the URL by simply appending the output key to "https://pastebin.com/"
+//
+// The request is validated with CreatePasteRequest.Validate before anything is sent to Pastebin.
+//
+// See CreatePasteWithResult if you need more than the paste key.
 func (c *Client) CreatePaste(request *CreatePasteRequest) (string, error) {
-	if request.Visibility == VisibilityPrivate && len(c.sessionKey) == 0 {
-		return "", ErrNotAuthenticated
+	result, err := c.createPaste(request, true)
+	if err != nil {
+		return "", err
+	}
+	return result.Key, nil
+}
+
+// CreatePasteWithResult creates a new paste and returns its key, its URLs and the metadata it was created with.
+// Unlike CreatePaste, which only returns the paste key, this makes it possible to share a complete link to the paste
+// without sending another request.
+func (c *Client) CreatePasteWithResult(request *CreatePasteRequest) (*CreatePasteResult, error) {
+	return c.createPaste(request, true)
+}
+
+// createPaste creates a new paste and returns the result.
+// The request is first checked against the policy configured with WithPolicy, if any, and then validated.
+// If scanContent is true, the content is passed through the scanner configured with WithContentScanner first.
+func (c *Client) createPaste(request *CreatePasteRequest, scanContent bool) (*CreatePasteResult, error) {
+	startedAt := time.Now()
+	if c.policy != nil {
+		var err error
+		if request, err = c.policy.Apply(request); err != nil {
+			return nil, err
+		}
+	}
+	if err := request.Validate(c.Capabilities()); err != nil {
+		return nil, err
+	}
+	code := request.Code
+	if scanContent {
+		var err error
+		if code, err = c.scanContent(code); err != nil {
+			return nil, err
+		}
 	}
 	expirationField := ExpirationNever
 	if len(request.Expiration) > 0 {
 		expirationField = request.Expiration
 	}
-	responseBody, err := c.doPastebinRequest(PostApiUrl, url.Values{
+	responseBody, err := c.doPastebinRequest(OperationCreatePaste, PostApiUrl, url.Values{
 		"api_option":            {"paste"},
-		"api_user_key":          {c.sessionKey},
+		"api_user_key":          {c.getSessionKey()},
 		"api_dev_key":           {c.developerApiKey},
 		"api_paste_name":        {request.Title},
-		"api_paste_code":        {request.Code},
+		"api_paste_code":        {code},
 		"api_paste_format":      {request.Syntax},
 		"api_paste_expire_date": {string(expirationField)},
 		"api_paste_private":     {fmt.Sprintf("%d", request.Visibility)},
 	}, true)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return strings.TrimPrefix(string(responseBody), "https://pastebin.com/"), nil
+	result := newCreatePasteResult(c.URLBuilder(), strings.TrimPrefix(string(responseBody), "https://pastebin.com/"), request.Title, request.Syntax, expirationField, request.Visibility, len(code), startedAt)
+	result.content = code
+	return result, nil
 }
 
-// DeletePaste removes a paste owned by the authenticated user
+// DeletePaste removes a paste owned by the authenticated user.
+// If the client has a Trash (see WithTrash), the paste is stored in the trash before being deleted.
 func (c *Client) DeletePaste(pasteKey string) error {
-	if len(c.sessionKey) == 0 {
+	return c.deletePaste(pasteKey, nil)
+}
+
+// deletePaste removes a paste owned by the authenticated user.
+// The metadata of the paste, if the caller already has it, is what gets stored in the trash.
+func (c *Client) deletePaste(pasteKey string, paste *Paste) error {
+	if len(c.getSessionKey()) == 0 {
 		return ErrNotAuthenticated
 	}
-	_, err := c.doPastebinRequest(RawApiUrl, url.Values{
+	if c.trash != nil {
+		if err := c.trashPaste(pasteKey, paste); err != nil {
+			return err
+		}
+	}
+	_, err := c.doPastebinRequest(OperationDeletePaste, RawApiUrl, url.Values{
 		"api_option":    {"delete"},
-		"api_user_key":  {c.sessionKey},
+		"api_user_key":  {c.getSessionKey()},
 		"api_dev_key":   {c.developerApiKey},
 		"api_paste_key": {pasteKey},
 	}, true)
-	return err
+	if err != nil {
+		// Only Pastebin refusing to delete the paste means that it still exists, so that it must not be restorable.
+		// After a transport error, the paste may have been deleted anyway, so its snapshot is kept.
+		if c.trash != nil && isAPIError([]byte(err.Error())) {
+			_ = c.trash.Remove(pasteKey)
+		}
+		return err
+	}
+	c.forgetPaste(pasteKey)
+	return nil
 }
 
 // GetAllUserPastes retrieves a list of pastes owned by the authenticated user
+//
+// Only the 100 most recent pastes are returned.
 func (c *Client) GetAllUserPastes() ([]*Paste, error) {
-	if len(c.sessionKey) == 0 {
-		return nil, ErrNotAuthenticated
+	pastes, _, err := c.listUserPastes(userPastesLimit)
+	return pastes, err
+}
+
+// getAllUserPastes retrieves the MaxUserPastes most recent pastes owned by the authenticated user, and whether the
+// list may be truncated
+func (c *Client) getAllUserPastes() ([]*Paste, bool, error) {
+	return c.listUserPastes(MaxUserPastes)
+}
+
+// listUserPastes retrieves the limit most recent pastes owned by the authenticated user, and whether the list may be
+// truncated
+func (c *Client) listUserPastes(limit int) ([]*Paste, bool, error) {
+	if len(c.getSessionKey()) == 0 {
+		return nil, false, ErrNotAuthenticated
 	}
-	responseBody, err := c.doPastebinRequest(PostApiUrl, url.Values{
+	responseBody, err := c.doPastebinRequest(OperationGetAllUserPastes, PostApiUrl, url.Values{
 		"api_option":        {"list"},
-		"api_user_key":      {c.sessionKey},
+		"api_user_key":      {c.getSessionKey()},
 		"api_dev_key":       {c.developerApiKey},
-		"api_results_limit": {"100"},
+		"api_results_limit": {strconv.Itoa(limit)},
 	}, true)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	var xmlPastes xmlPastes
 	err = xml.Unmarshal([]byte(fmt.Sprintf("<pastes>%s</pastes>", string(responseBody))), &xmlPastes)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	var pastes []*Paste
 	for _, xmlPaste := range xmlPastes.Pastes {
 		pastes = append(pastes, xmlPaste.ToPaste(c.username))
 	}
-	return pastes, nil
+	c.rememberPasteExpirations(pastes...)
+	return pastes, len(pastes) >= limit, nil
 }
 
 // GetUserPasteContent retrieves the content of a paste owned by the authenticated user
 // Unlike GetPasteContent, this function can only get the content of a paste that belongs to the authenticated user,
 // even if the paste is public.
 func (c *Client) GetUserPasteContent(pasteKey string) (string, error) {
-	if len(c.sessionKey) == 0 {
+	if len(c.getSessionKey()) == 0 {
 		return "", ErrNotAuthenticated
 	}
-	responseBody, err := c.doPastebinRequest(RawApiUrl, url.Values{
+	responseBody, err := c.doPastebinRequest(OperationGetUserPasteContent, RawApiUrl, url.Values{
 		"api_option":    {"show_paste"},
-		"api_user_key":  {c.sessionKey},
+		"api_user_key":  {c.getSessionKey()},
 		"api_dev_key":   {c.developerApiKey},
 		"api_paste_key": {pasteKey},
 	}, true)
@@ -155,9 +259,28 @@ func (c *Client) GetUserPasteContent(pasteKey string) (string, error) {
 	return string(responseBody), nil
 }
 
+// fetchPasteContent retrieves the content of a paste through GetUserPasteContent if the client is authenticated,
+// falling back to GetPasteContent if the client is not authenticated or if the paste does not belong to the user
+func (c *Client) fetchPasteContent(pasteKey string) (string, error) {
+	if len(c.getSessionKey()) > 0 {
+		if content, err := c.GetUserPasteContent(pasteKey); err == nil {
+			return content, nil
+		}
+	}
+	return c.GetPasteContent(pasteKey)
+}
+
+// getSessionKey returns the api_user_key of the authenticated user, or an empty string if the client is not
+// authenticated
+func (c *Client) getSessionKey() string {
+	c.sessionKeyMutex.RLock()
+	defer c.sessionKeyMutex.RUnlock()
+	return c.sessionKey
+}
+
 // login authenticates the user and sets sessionKey to the returned api_user_key
 func (c *Client) login() error {
-	responseBody, err := c.doPastebinRequest(LoginApiUrl, url.Values{
+	responseBody, err := c.doPastebinRequest(OperationLogin, LoginApiUrl, url.Values{
 		"api_user_name":     {c.username},
 		"api_user_password": {c.password},
 		"api_dev_key":       {c.developerApiKey},
@@ -165,67 +288,173 @@ func (c *Client) login() error {
 	if err != nil {
 		return err
 	}
+	c.sessionKeyMutex.Lock()
 	c.sessionKey = string(responseBody)
+	c.sessionKeyMutex.Unlock()
 	return nil
 }
 
 // doPastebinRequest performs an HTTP request to the provided Pastebin API URL with the given fields
 // If reAuthenticateOnInvalidSessionKey is true, will automatically attempt to re-login on invalid api_user_key
-func (c *Client) doPastebinRequest(apiUrl string, fields url.Values, reAuthenticateOnInvalidSessionKey bool) ([]byte, error) {
-	client := getHTTPClient()
+func (c *Client) doPastebinRequest(operation Operation, apiUrl string, fields url.Values, reAuthenticateOnInvalidSessionKey bool) ([]byte, error) {
 	request, err := http.NewRequest("POST", apiUrl, bytes.NewBuffer([]byte(fields.Encode())))
 	if err != nil {
 		return nil, err
 	}
 	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	response, err := client.Do(request)
+	response, body, err := c.doRequest(operation, fields.Get("api_paste_key"), request)
 	if err != nil {
 		return nil, err
 	}
-	defer response.Body.Close()
 	if response.StatusCode != 200 {
 		return nil, errors.New(response.Status)
 	}
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		return nil, err
-	}
 	if reAuthenticateOnInvalidSessionKey && string(body) == "Bad API request, invalid api_user_key" {
 		err = c.login()
+		c.notify(func(observer Observer) {
+			observer.OnReLogin(&ReLoginEvent{Operation: operation, Err: err})
+		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to re-authenticate on invalid api_user_key response: %s", err.Error())
 		}
-		// Retry the request one more time
-		return c.doPastebinRequest(apiUrl, fields, false)
+		c.notify(func(observer Observer) {
+			observer.OnRetry(&RetryEvent{Operation: operation, Attempt: 2, Reason: "invalid api_user_key"})
+		})
+		// Retry the request one more time, with the new session key
+		retryFields := url.Values{}
+		for name, values := range fields {
+			retryFields[name] = values
+		}
+		retryFields.Set("api_user_key", c.getSessionKey())
+		return c.doPastebinRequest(operation, apiUrl, retryFields, false)
 	}
-	if strings.HasPrefix(string(body), "Bad API request") || strings.HasPrefix(string(body), "Error") {
+	if isAPIError(body) {
 		return nil, errors.New(string(body))
 	}
 	return body, nil
 }
 
-// GetPasteContent retrieves the content of a paste by using the raw endpoint (https://pastebin.com/raw/{pasteKey})
-// This does not require authentication, but only works with public and unlisted pastes.
+// doRequest sends the request using the shared HTTP client and reads the response body, which may not exceed
+// the maximum response size configured for the operation.
 //
-// WARNING: Using this excessively could lead to your IP being blocked.
-// You may want to use GetPasteContentUsingScrapingAPI instead.
-func GetPasteContent(pasteKey string) (string, error) {
-	client := getHTTPClient()
-	request, err := http.NewRequest("GET", RawUrlPrefix+"/"+pasteKey, nil)
+// This is the only function that sends requests, which makes it responsible for the rate limit and for notifying
+// the observers of the client.
+func (c *Client) doRequest(operation Operation, pasteKey string, request *http.Request) (*http.Response, []byte, error) {
+	c.waitForRateLimit(operation)
+	if !c.isObserved() {
+		return c.sendRequest(operation, request)
+	}
+	trace := &requestTrace{startedAt: time.Now()}
+	request = request.WithContext(httptrace.WithClientTrace(request.Context(), trace.clientTrace()))
+	c.notify(func(observer Observer) {
+		observer.OnRequestStart(&RequestStartEvent{
+			Operation: operation,
+			Method:    request.Method,
+			URL:       request.URL.String(),
+			PasteKey:  pasteKey,
+			StartedAt: trace.startedAt,
+		})
+	})
+	response, body, err := c.sendRequest(operation, request)
+	event := &RequestFinishEvent{
+		Operation: operation,
+		Method:    request.Method,
+		URL:       request.URL.String(),
+		PasteKey:  pasteKey,
+		BytesRead: int64(len(body)),
+		BytesSent: max(request.ContentLength, 0),
+		Duration:  time.Since(trace.startedAt),
+		Timings:   trace.getTimings(),
+		Err:       err,
+	}
+	if response != nil {
+		event.StatusCode = response.StatusCode
+	}
+	if isAPIError(body) {
+		event.APIError = string(body)
+	}
+	var tooLargeErr *ResponseTooLargeError
+	if errors.As(err, &tooLargeErr) {
+		event.BytesRead = tooLargeErr.BytesRead
+	}
+	c.notify(func(observer Observer) {
+		observer.OnRequestFinish(event)
+	})
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
-	response, err := client.Do(request)
+	return response, body, nil
+}
+
+// sendRequest sends the request and reads the response body.
+// Unlike doRequest, the response is returned even if reading the body failed.
+func (c *Client) sendRequest(operation Operation, request *http.Request) (*http.Response, []byte, error) {
+	response, err := getHTTPClient().Do(request)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
 	defer response.Body.Close()
-	body, err := io.ReadAll(response.Body)
+	body, err := readResponseBody(operation, response.Body, c.maxResponseSize(operation))
 	if err != nil {
-		return "", err
+		return response, nil, err
 	}
-	if response.StatusCode != 200 || strings.HasPrefix(string(body), "Bad API request") || strings.HasPrefix(string(body), "Error") {
-		return "", errors.New(string(body))
+	return response, body, nil
+}
+
+// doPublicRequest performs an HTTP request against one of Pastebin's endpoints that does not require authentication
+func (c *Client) doPublicRequest(operation Operation, pasteKey, method, requestUrl string) ([]byte, error) {
+	request, err := http.NewRequest(method, requestUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if method == "POST" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	response, body, err := c.doRequest(operation, pasteKey, request)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkPublicResponse(response, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// checkPublicResponse returns an error if the response of one of Pastebin's endpoints that does not require
+// authentication indicates a failure
+func checkPublicResponse(response *http.Response, body []byte) error {
+	if response.StatusCode != 200 || isAPIError(body) {
+		return errors.New(string(body))
+	}
+	return nil
+}
+
+// isAPIError returns whether the body is an error message, which Pastebin returns with a 200 status code
+func isAPIError(body []byte) bool {
+	return strings.HasPrefix(string(body), "Bad API request") || strings.HasPrefix(string(body), "Error")
+}
+
+// GetPasteContent retrieves the content of a paste by using the raw endpoint (https://pastebin.com/raw/{pasteKey})
+// This does not require authentication, but only works with public and unlisted pastes.
+//
+// WARNING: Using this excessively could lead to your IP being blocked.
+// You may want to use GetPasteContentUsingScrapingAPI instead.
+func GetPasteContent(pasteKey string) (string, error) {
+	return (&Client{}).GetPasteContent(pasteKey)
+}
+
+// GetPasteContent retrieves the content of a paste by using the raw endpoint (https://pastebin.com/raw/{pasteKey})
+// Unlike the package-level GetPasteContent, this respects the configuration of the client.
+//
+// WARNING: Using this excessively could lead to your IP being blocked.
+// You may want to use GetPasteContentUsingScrapingAPI instead.
+func (c *Client) GetPasteContent(pasteKey string) (string, error) {
+	requestUrl := RawUrlPrefix + "/" + pasteKey
+	body, err := c.deduplicate(OperationGetPasteContent, requestUrl, func() ([]byte, error) {
+		return c.getCachedContent(OperationGetPasteContent, pasteKey, requestUrl)
+	})
+	if err != nil {
+		return "", err
 	}
 	return string(body), nil
 }
@@ -236,23 +465,22 @@ func GetPasteContent(pasteKey string) (string, error) {
 // To use the scraping API, you must link your IP to your Pastebin account, or it will not work.
 // See https://pastebin.com/doc_scraping_api
 func GetPasteContentUsingScrapingAPI(pasteKey string) (string, error) {
-	client := getHTTPClient()
-	request, err := http.NewRequest("GET", ScrapeItemApiUrl+"?"+url.Values{"i": {pasteKey}}.Encode(), nil)
-	if err != nil {
-		return "", err
-	}
-	response, err := client.Do(request)
-	if err != nil {
-		return "", err
-	}
-	defer response.Body.Close()
-	body, err := io.ReadAll(response.Body)
+	return (&Client{}).GetPasteContentUsingScrapingAPI(pasteKey)
+}
+
+// GetPasteContentUsingScrapingAPI retrieves the content of a paste by using the Scraping API (ScrapingApiUrl)
+// Unlike the package-level GetPasteContentUsingScrapingAPI, this respects the configuration of the client.
+//
+// To use the scraping API, you must link your IP to your Pastebin account, or it will not work.
+// See https://pastebin.com/doc_scraping_api
+func (c *Client) GetPasteContentUsingScrapingAPI(pasteKey string) (string, error) {
+	requestUrl := ScrapeItemApiUrl + "?" + url.Values{"i": {pasteKey}}.Encode()
+	body, err := c.deduplicate(OperationGetPasteContentUsingScrapingAPI, requestUrl, func() ([]byte, error) {
+		return c.getCachedContent(OperationGetPasteContentUsingScrapingAPI, pasteKey, requestUrl)
+	})
 	if err != nil {
 		return "", err
 	}
-	if response.StatusCode != 200 || strings.HasPrefix(string(body), "Bad API request") || strings.HasPrefix(string(body), "Error") {
-		return "", errors.New(string(body))
-	}
 	return string(body), nil
 }
 
@@ -262,29 +490,30 @@ func GetPasteContentUsingScrapingAPI(pasteKey string) (string, error) {
 // To use the scraping API, you must link your IP to your Pastebin account, or it will not work.
 // See https://pastebin.com/doc_scraping_api
 func GetPasteUsingScrapingAPI(pasteKey string) (*Paste, error) {
-	client := getHTTPClient()
-	request, err := http.NewRequest("GET", ScrapeItemMetadataApiUrl+"?"+url.Values{"i": {pasteKey}}.Encode(), nil)
-	if err != nil {
-		return nil, err
-	}
-	response, err := client.Do(request)
-	if err != nil {
-		return nil, err
-	}
-	defer response.Body.Close()
-	body, err := io.ReadAll(response.Body)
+	return (&Client{}).GetPasteUsingScrapingAPI(pasteKey)
+}
+
+// GetPasteUsingScrapingAPI retrieves the metadata of a paste by using the Scraping API (ScrapingApiUrl)
+// Unlike the package-level GetPasteUsingScrapingAPI, this respects the configuration of the client.
+//
+// To use the scraping API, you must link your IP to your Pastebin account, or it will not work.
+// See https://pastebin.com/doc_scraping_api
+func (c *Client) GetPasteUsingScrapingAPI(pasteKey string) (*Paste, error) {
+	requestUrl := ScrapeItemMetadataApiUrl + "?" + url.Values{"i": {pasteKey}}.Encode()
+	body, err := c.deduplicate(OperationGetPasteUsingScrapingAPI, requestUrl, func() ([]byte, error) {
+		return c.doPublicRequest(OperationGetPasteUsingScrapingAPI, pasteKey, "GET", requestUrl)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if response.StatusCode != 200 || strings.HasPrefix(string(body), "Bad API request") || strings.HasPrefix(string(body), "Error") {
-		return nil, errors.New(string(body))
-	}
 	var jsonPaste jsonPaste
 	err = json.Unmarshal(body, &jsonPaste)
 	if err != nil {
 		return nil, err
 	}
-	return jsonPaste.ToPaste(), nil
+	paste := jsonPaste.ToPaste()
+	c.rememberPasteExpirations(paste)
+	return paste, nil
 }
 
 // GetRecentPastesUsingScrapingAPI retrieves the most recent pastes using Pastebin's scraping API
@@ -294,24 +523,22 @@ func GetPasteUsingScrapingAPI(pasteKey string) (*Paste, error) {
 // To use the scraping API, you must link your IP to your Pastebin account, or it will not work.
 // See https://pastebin.com/doc_scraping_api
 func GetRecentPastesUsingScrapingAPI(syntax string, limit int) ([]*Paste, error) {
-	client := getHTTPClient()
-	request, err := http.NewRequest("POST", ScrapingApiUrl+"?"+url.Values{"lang": {syntax}, "limit": {strconv.Itoa(limit)}}.Encode(), nil)
-	if err != nil {
-		return nil, err
-	}
-	request.Header.Set("Content-Type", "application/json")
-	response, err := client.Do(request)
-	if err != nil {
-		return nil, err
-	}
-	defer response.Body.Close()
-	body, err := io.ReadAll(response.Body)
+	return (&Client{}).GetRecentPastesUsingScrapingAPI(syntax, limit)
+}
+
+// GetRecentPastesUsingScrapingAPI retrieves the most recent pastes using Pastebin's scraping API
+// Unlike the package-level GetRecentPastesUsingScrapingAPI, this respects the configuration of the client.
+//
+// To use the scraping API, you must link your IP to your Pastebin account, or it will not work.
+// See https://pastebin.com/doc_scraping_api
+func (c *Client) GetRecentPastesUsingScrapingAPI(syntax string, limit int) ([]*Paste, error) {
+	requestUrl := ScrapingApiUrl + "?" + url.Values{"lang": {syntax}, "limit": {strconv.Itoa(limit)}}.Encode()
+	body, err := c.deduplicate(OperationGetRecentPastesUsingScrapingAPI, requestUrl, func() ([]byte, error) {
+		return c.doPublicRequest(OperationGetRecentPastesUsingScrapingAPI, "", "POST", requestUrl)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if response.StatusCode != 200 || strings.HasPrefix(string(body), "Bad API request") || strings.HasPrefix(string(body), "Error") {
-		return nil, errors.New(string(body))
-	}
 	var jsonPastes jsonPastes
 	// the output isn't formatted properly, so we'll cheat a bit
 	err = json.Unmarshal([]byte(fmt.Sprintf("{\"pastes\":%s}", string(body))), &jsonPastes)
@@ -322,5 +549,11 @@ func GetRecentPastesUsingScrapingAPI(syntax string, limit int) ([]*Paste, error)
 	for _, jsonPaste := range jsonPastes.Pastes {
 		pastes = append(pastes, jsonPaste.ToPaste())
 	}
+	c.rememberPasteExpirations(pastes...)
+	c.notify(func(observer Observer) {
+		if recentPastesObserver, ok := observer.(RecentPastesObserver); ok {
+			recentPastesObserver.OnRecentPastes(pastes)
+		}
+	})
 	return pastes, nil
 }
@@ -2,8 +2,10 @@ package pastebin
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/TwiN/go-pastebin/test"
@@ -92,7 +94,7 @@ func TestClient_CreatePaste(t *testing.T) {
 		}
 	})}
 	client, _ := NewClient("username", "password", "token")
-	pasteKey, err := client.CreatePaste(NewCreatePasteRequest("", "", ExpirationTenMinutes, VisibilityPublic, ""))
+	pasteKey, err := client.CreatePaste(NewCreatePasteRequest("", "content", ExpirationTenMinutes, VisibilityPublic, ""))
 	if err != nil {
 		t.Error("shouldn't have returned an error")
 	}
@@ -103,8 +105,8 @@ func TestClient_CreatePaste(t *testing.T) {
 
 func TestClient_CreatePasteWithPrivateVisibility(t *testing.T) {
 	client, _ := NewClient("", "", "token")
-	_, err := client.CreatePaste(NewCreatePasteRequest("", "", ExpirationTenMinutes, VisibilityPrivate, ""))
-	if err != ErrNotAuthenticated {
+	_, err := client.CreatePaste(NewCreatePasteRequest("", "content", ExpirationTenMinutes, VisibilityPrivate, ""))
+	if !errors.Is(err, ErrNotAuthenticated) {
 		t.Error("CreatePaste should've returned ErrNotAuthenticated, because only a client configured with a username and password can create a private paste")
 	}
 }
@@ -123,7 +125,7 @@ func TestClient_CreatePasteWhenHTTPRequestReturnsError(t *testing.T) {
 		}
 	})}
 	client, _ := NewClient("username", "password", "token")
-	_, err := client.CreatePaste(NewCreatePasteRequest("", "", ExpirationTenMinutes, VisibilityPublic, ""))
+	_, err := client.CreatePaste(NewCreatePasteRequest("", "content", ExpirationTenMinutes, VisibilityPublic, ""))
 	if err == nil {
 		t.Error("should've returned an error")
 	}
@@ -152,7 +154,7 @@ func TestClient_CreatePasteWhenSessionKeyExpired(t *testing.T) {
 		}
 	})}
 	client, _ := NewClient("username", "password", "token")
-	pasteKey, err := client.CreatePaste(NewCreatePasteRequest("", "", ExpirationTenMinutes, VisibilityPublic, ""))
+	pasteKey, err := client.CreatePaste(NewCreatePasteRequest("", "content", ExpirationTenMinutes, VisibilityPublic, ""))
 	if err != nil {
 		t.Fatal("shouldn't have returned an error")
 	}
@@ -188,7 +190,13 @@ func TestClient_GetUserPasteContent(t *testing.T) {
 }
 
 func TestClient_GetAllUserPastes(t *testing.T) {
+	var resultsLimit string
 	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		if request.Body != nil {
+			body, _ := io.ReadAll(request.Body)
+			fields, _ := url.ParseQuery(string(body))
+			resultsLimit = fields.Get("api_results_limit")
+		}
 		return &http.Response{
 			StatusCode: 200,
 			Body: io.NopCloser(bytes.NewBufferString(`<paste>
@@ -213,6 +221,9 @@ func TestClient_GetAllUserPastes(t *testing.T) {
 	if len(pastes) != 1 {
 		t.Error("should've returned 1 paste, but returned", len(pastes))
 	}
+	if resultsLimit != "100" {
+		t.Errorf("expected the 100 most recent pastes to be requested, got a limit of %s", resultsLimit)
+	}
 	if ExpectedUser := "username"; pastes[0].User != ExpectedUser {
 		t.Errorf("expected User to be '%s', got '%s'", ExpectedUser, pastes[0].User)
 	}
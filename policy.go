@@ -0,0 +1,355 @@
+package pastebin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrPolicyViolation = errors.New("paste request violates policy")
+)
+
+// PolicyMode is what happens to a CreatePasteRequest that does not comply with a Policy
+type PolicyMode string
+
+const (
+	// PolicyModeReject rejects requests that do not comply with the policy with a *PolicyViolationError
+	PolicyModeReject PolicyMode = "reject"
+
+	// PolicyModeRewrite rewrites requests that do not comply with the policy so that they do
+	PolicyModeRewrite PolicyMode = "rewrite"
+)
+
+// Policy constrains the fields of the CreatePasteRequest passed to a Client configured with WithPolicy
+//
+// A field left to its zero value does not constrain anything.
+type Policy struct {
+	// AllowedVisibilities are the visibilities pastes may be created with.
+	// When rewriting, the visibility is replaced by the least private allowed visibility that is more private than
+	// the requested visibility, or by the most private allowed visibility if there's none.
+	AllowedVisibilities []Visibility
+
+	// MaxExpiration is the longest expiration pastes may be created with.
+	// Note that a request with no expiration is treated as ExpirationNever.
+	MaxExpiration Expiration
+
+	// RequiredTitlePrefix is the prefix every title must start with.
+	// When rewriting, the prefix is prepended to the title.
+	RequiredTitlePrefix string
+
+	// AllowedSyntaxes are the syntaxes pastes may be created with.
+	// When rewriting, the syntax is replaced by "text" if it is allowed, or by the first allowed syntax otherwise.
+	AllowedSyntaxes []string
+
+	// Mode is what happens to requests that do not comply with the policy. Defaults to PolicyModeReject.
+	Mode PolicyMode
+}
+
+// policyJSON is the JSON representation of a Policy, in which visibilities are named rather than numbered
+type policyJSON struct {
+	AllowedVisibilities []policyVisibility `json:"allowed_visibilities,omitempty"`
+	MaxExpiration       Expiration         `json:"max_expiration,omitempty"`
+	RequiredTitlePrefix string             `json:"required_title_prefix,omitempty"`
+	AllowedSyntaxes     []string           `json:"allowed_syntaxes,omitempty"`
+	Mode                PolicyMode         `json:"mode,omitempty"`
+}
+
+// policyVisibility is the name of a Visibility (public, unlisted or private) in a policy file
+type policyVisibility string
+
+// MarshalJSON implements json.Marshaler
+func (p Policy) MarshalJSON() ([]byte, error) {
+	document := policyJSON{MaxExpiration: p.MaxExpiration, RequiredTitlePrefix: p.RequiredTitlePrefix, AllowedSyntaxes: p.AllowedSyntaxes, Mode: p.Mode}
+	for _, visibility := range p.AllowedVisibilities {
+		document.AllowedVisibilities = append(document.AllowedVisibilities, policyVisibility(visibility.String()))
+	}
+	return json.Marshal(document)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (p *Policy) UnmarshalJSON(data []byte) error {
+	var document policyJSON
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	return p.fromJSON(&document)
+}
+
+// fromJSON sets the fields of the policy to those of its JSON representation
+func (p *Policy) fromJSON(document *policyJSON) error {
+	var allowedVisibilities []Visibility
+	for _, name := range document.AllowedVisibilities {
+		visibility, err := ParseVisibility(string(name))
+		if err != nil {
+			return fmt.Errorf("allowed_visibilities: %w", err)
+		}
+		allowedVisibilities = append(allowedVisibilities, visibility)
+	}
+	*p = Policy{
+		AllowedVisibilities: allowedVisibilities,
+		MaxExpiration:       document.MaxExpiration,
+		RequiredTitlePrefix: document.RequiredTitlePrefix,
+		AllowedSyntaxes:     document.AllowedSyntaxes,
+		Mode:                document.Mode,
+	}
+	return nil
+}
+
+// PolicyViolationError is returned when a CreatePasteRequest does not comply with the Policy of a client
+// configured with PolicyModeReject
+type PolicyViolationError struct {
+	Violations []string
+}
+
+func (e *PolicyViolationError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrPolicyViolation.Error(), strings.Join(e.Violations, "; "))
+}
+
+// Is allows errors.Is(err, ErrPolicyViolation) to match a *PolicyViolationError
+func (e *PolicyViolationError) Is(target error) bool {
+	return target == ErrPolicyViolation
+}
+
+// Validate returns an error if the policy itself is invalid
+func (p *Policy) Validate() error {
+	var errs []error
+	for _, visibility := range p.AllowedVisibilities {
+		if visibility.String() == "unknown" {
+			errs = append(errs, fmt.Errorf("allowed_visibilities: unknown visibility %d", visibility))
+		}
+	}
+	if len(p.MaxExpiration) > 0 && !p.MaxExpiration.IsValid() {
+		errs = append(errs, fmt.Errorf("max_expiration: unknown expiration %q", p.MaxExpiration))
+	}
+	if len(p.Mode) > 0 && p.Mode != PolicyModeReject && p.Mode != PolicyModeRewrite {
+		errs = append(errs, fmt.Errorf("mode: unknown mode %q", p.Mode))
+	}
+	return errors.Join(errs...)
+}
+
+// Apply checks whether the request complies with the policy.
+//
+// If it does, the request is returned as is. If it doesn't, a rewritten copy of the request is returned if the mode
+// of the policy is PolicyModeRewrite, and a *PolicyViolationError is returned otherwise.
+func (p *Policy) Apply(request *CreatePasteRequest) (*CreatePasteRequest, error) {
+	var violations []string
+	rewritten := *request
+	if len(p.AllowedVisibilities) > 0 && !containsVisibility(p.AllowedVisibilities, request.Visibility) {
+		violations = append(violations, fmt.Sprintf("visibility %s is not allowed", request.Visibility))
+		rewritten.Visibility = p.closestAllowedVisibility(request.Visibility)
+	}
+	if len(p.MaxExpiration) > 0 {
+		expiration := request.Expiration
+		if len(expiration) == 0 {
+			expiration = ExpirationNever
+		}
+		if expiration.isLongerThan(p.MaxExpiration) {
+			violations = append(violations, fmt.Sprintf("expiration %s exceeds the maximum expiration %s", expiration, p.MaxExpiration))
+			rewritten.Expiration = p.MaxExpiration
+		}
+	}
+	if len(p.RequiredTitlePrefix) > 0 && !strings.HasPrefix(request.Title, p.RequiredTitlePrefix) {
+		violations = append(violations, fmt.Sprintf("title must start with %q", p.RequiredTitlePrefix))
+		rewritten.Title = p.RequiredTitlePrefix + request.Title
+	}
+	if len(p.AllowedSyntaxes) > 0 && !containsString(p.AllowedSyntaxes, request.Syntax) {
+		violations = append(violations, fmt.Sprintf("syntax %q is not allowed", request.Syntax))
+		rewritten.Syntax = p.AllowedSyntaxes[0]
+		if containsString(p.AllowedSyntaxes, "text") {
+			rewritten.Syntax = "text"
+		}
+	}
+	if len(violations) == 0 {
+		return request, nil
+	}
+	if p.Mode == PolicyModeRewrite {
+		return &rewritten, nil
+	}
+	return nil, &PolicyViolationError{Violations: violations}
+}
+
+// closestAllowedVisibility returns the least private allowed visibility that is more private than the visibility,
+// or the most private allowed visibility if there's none
+func (p *Policy) closestAllowedVisibility(visibility Visibility) Visibility {
+	closest, mostPrivate := Visibility(-1), p.AllowedVisibilities[0]
+	for _, allowed := range p.AllowedVisibilities {
+		if allowed > visibility && (closest == -1 || allowed < closest) {
+			closest = allowed
+		}
+		if allowed > mostPrivate {
+			mostPrivate = allowed
+		}
+	}
+	if closest == -1 {
+		return mostPrivate
+	}
+	return closest
+}
+
+func containsVisibility(visibilities []Visibility, visibility Visibility) bool {
+	for _, v := range visibilities {
+		if v == visibility {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// WithPolicy configures the client to apply the policy to every CreatePasteRequest before the paste is created.
+// Passing nil removes the policy.
+//
+// Returns the client to allow chaining
+func (c *Client) WithPolicy(policy *Policy) *Client {
+	c.policy = policy
+	return c
+}
+
+// LoadPolicy reads a policy from a JSON or YAML file, depending on the extension of the file (.json, .yaml or .yml)
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ParsePolicyJSON(data)
+	case ".yaml", ".yml":
+		return ParsePolicyYAML(data)
+	default:
+		return nil, fmt.Errorf("unsupported policy file extension %q, must be .json, .yaml or .yml", filepath.Ext(path))
+	}
+}
+
+// ParsePolicyJSON parses and validates a policy in the JSON format, e.g.
+//
+//	{"allowed_visibilities": ["unlisted", "private"], "max_expiration": "1W", "mode": "rewrite"}
+func ParsePolicyJSON(data []byte) (*Policy, error) {
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	var document policyJSON
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	policy := &Policy{}
+	if err := policy.fromJSON(&document); err != nil {
+		return nil, err
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ParsePolicyYAML parses and validates a policy in the YAML format, e.g.
+//
+//	allowed_visibilities: [unlisted, private]
+//	max_expiration: 1W
+//	allowed_syntaxes:
+//	  - text
+//	  - go
+//	mode: rewrite
+//
+// Only the subset of YAML needed to express a policy is supported: top-level keys with scalar values,
+// flow sequences and block sequences, as well as comments.
+func ParsePolicyYAML(data []byte) (*Policy, error) {
+	document, err := parseFlatYAML(string(data))
+	if err != nil {
+		return nil, err
+	}
+	jsonData, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePolicyJSON(jsonData)
+}
+
+// parseFlatYAML parses a YAML document made of top-level keys whose values are either scalars or sequences of scalars
+func parseFlatYAML(document string) (map[string]any, error) {
+	values := make(map[string]any)
+	var currentSequenceKey string
+	for lineNumber, line := range strings.Split(strings.ReplaceAll(document, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(stripYAMLComment(line), " \t")
+		if len(strings.TrimSpace(line)) == 0 || line == "---" {
+			continue
+		}
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if len(currentSequenceKey) == 0 || line[0] != ' ' && line[0] != '-' {
+				return nil, fmt.Errorf("line %d: unexpected sequence item", lineNumber+1)
+			}
+			sequence, _ := values[currentSequenceKey].([]any)
+			values[currentSequenceKey] = append(sequence, parseYAMLScalar(strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))))
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			return nil, fmt.Errorf("line %d: nested mappings are not supported", lineNumber+1)
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected key: value", lineNumber+1)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		currentSequenceKey = ""
+		switch {
+		case len(value) == 0:
+			// The value is either a block sequence on the following lines, or null
+			currentSequenceKey = key
+			values[key] = nil
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			sequence := []any{}
+			if inner := strings.TrimSpace(value[1 : len(value)-1]); len(inner) > 0 {
+				for _, item := range strings.Split(inner, ",") {
+					sequence = append(sequence, parseYAMLScalar(strings.TrimSpace(item)))
+				}
+			}
+			values[key] = sequence
+		default:
+			values[key] = parseYAMLScalar(value)
+		}
+	}
+	return values, nil
+}
+
+// parseYAMLScalar parses a scalar, keeping it as a string unless it is quoted, in which case the quotes are removed
+func parseYAMLScalar(value string) any {
+	if len(value) >= 2 && (value[0] == '"' && value[len(value)-1] == '"') {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+	}
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	return value
+}
+
+// stripYAMLComment removes the comment from the line, ignoring '#' characters within quotes
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch {
+		case quote != 0:
+			if line[i] == quote {
+				quote = 0
+			}
+		case line[i] == '"' || line[i] == '\'':
+			quote = line[i]
+		case line[i] == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
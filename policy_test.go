@@ -0,0 +1,212 @@
+package pastebin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func TestPolicy_Apply(t *testing.T) {
+	policy := &Policy{
+		AllowedVisibilities: []Visibility{VisibilityUnlisted, VisibilityPrivate},
+		MaxExpiration:       ExpirationOneWeek,
+		RequiredTitlePrefix: "[acme] ",
+		AllowedSyntaxes:     []string{"go", "text"},
+		Mode:                PolicyModeRewrite,
+	}
+	testCases := []struct {
+		desc     string
+		request  *CreatePasteRequest
+		expected CreatePasteRequest
+	}{
+		{
+			desc:     "compliant-request",
+			request:  NewCreatePasteRequest("[acme] logs", "content", ExpirationOneDay, VisibilityUnlisted, "go"),
+			expected: CreatePasteRequest{Title: "[acme] logs", Code: "content", Expiration: ExpirationOneDay, Visibility: VisibilityUnlisted, Syntax: "go"},
+		},
+		{
+			desc:     "non-compliant-request",
+			request:  NewCreatePasteRequest("logs", "content", ExpirationOneMonth, VisibilityPublic, "python"),
+			expected: CreatePasteRequest{Title: "[acme] logs", Code: "content", Expiration: ExpirationOneWeek, Visibility: VisibilityUnlisted, Syntax: "text"},
+		},
+		{
+			desc:     "no-expiration-is-treated-as-never",
+			request:  NewCreatePasteRequest("[acme] logs", "content", "", VisibilityPrivate, "go"),
+			expected: CreatePasteRequest{Title: "[acme] logs", Code: "content", Expiration: ExpirationOneWeek, Visibility: VisibilityPrivate, Syntax: "go"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			rewritten, err := policy.Apply(tC.request)
+			if err != nil {
+				t.Fatal("shouldn't have returned an error, got", err)
+			}
+			if *rewritten != tC.expected {
+				t.Errorf("expected %+v, got %+v", tC.expected, *rewritten)
+			}
+		})
+	}
+	policy.Mode = PolicyModeReject
+	_, err := policy.Apply(NewCreatePasteRequest("logs", "content", ExpirationOneMonth, VisibilityPublic, "python"))
+	var violationErr *PolicyViolationError
+	if !errors.As(err, &violationErr) || !errors.Is(err, ErrPolicyViolation) {
+		t.Fatalf("expected a *PolicyViolationError, got %v", err)
+	}
+	if len(violationErr.Violations) != 4 {
+		t.Errorf("expected 4 violations, got %d: %v", len(violationErr.Violations), violationErr.Violations)
+	}
+}
+
+func TestPolicy_ApplyWhenNoAllowedVisibilityIsMorePrivate(t *testing.T) {
+	policy := &Policy{AllowedVisibilities: []Visibility{VisibilityPublic, VisibilityUnlisted}, Mode: PolicyModeRewrite}
+	rewritten, err := policy.Apply(NewCreatePasteRequest("", "content", ExpirationNever, VisibilityPrivate, ""))
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if rewritten.Visibility != VisibilityUnlisted {
+		t.Errorf("expected %s, got %s", VisibilityUnlisted, rewritten.Visibility)
+	}
+}
+
+func TestClient_WithPolicy(t *testing.T) {
+	var requests int
+	var fieldsSent url.Values
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		requests++
+		body, _ := io.ReadAll(request.Body)
+		fieldsSent, _ = url.ParseQuery(string(body))
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("https://pastebin.com/abcdefgh"))}
+	})}
+	client, _ := NewClient("", "", "token")
+	client.WithPolicy(&Policy{AllowedVisibilities: []Visibility{VisibilityUnlisted}, MaxExpiration: ExpirationOneDay})
+	if _, err := client.CreatePaste(NewCreatePasteRequest("", "content", ExpirationNever, VisibilityPublic, "")); !errors.Is(err, ErrPolicyViolation) {
+		t.Fatalf("expected %v, got %v", ErrPolicyViolation, err)
+	}
+	if requests != 0 {
+		t.Fatal("no request should have been sent for a request rejected by the policy")
+	}
+	client.WithPolicy(&Policy{AllowedVisibilities: []Visibility{VisibilityUnlisted}, MaxExpiration: ExpirationOneDay, Mode: PolicyModeRewrite})
+	request := NewCreatePasteRequest("", "content", ExpirationNever, VisibilityPublic, "")
+	if _, err := client.CreatePaste(request); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if fieldsSent.Get("api_paste_private") != "1" || fieldsSent.Get("api_paste_expire_date") != string(ExpirationOneDay) {
+		t.Errorf("expected the request to be rewritten, got %v", fieldsSent)
+	}
+	if request.Visibility != VisibilityPublic {
+		t.Error("the request passed to CreatePaste should not have been modified")
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	expected := Policy{
+		AllowedVisibilities: []Visibility{VisibilityUnlisted, VisibilityPrivate},
+		MaxExpiration:       ExpirationOneWeek,
+		RequiredTitlePrefix: "[acme] ",
+		AllowedSyntaxes:     []string{"text", "go"},
+		Mode:                PolicyModeRewrite,
+	}
+	testCases := []struct {
+		fileName string
+		content  string
+	}{
+		{
+			fileName: "policy.json",
+			content:  `{"allowed_visibilities": ["unlisted", "private"], "max_expiration": "1W", "required_title_prefix": "[acme] ", "allowed_syntaxes": ["text", "go"], "mode": "rewrite"}`,
+		},
+		{
+			fileName: "policy.yaml",
+			content:  "# Organisation policy\nallowed_visibilities: [unlisted, private]\nmax_expiration: 1W\nrequired_title_prefix: \"[acme] \" # quoted to keep the space\nallowed_syntaxes:\n  - text\n  - go\nmode: rewrite\n",
+		},
+		{
+			fileName: "policy.yml",
+			content:  "allowed_visibilities:\n- unlisted\n- private\nmax_expiration: '1W'\nrequired_title_prefix: '[acme] '\nallowed_syntaxes: [text, go]\nmode: rewrite",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.fileName, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tC.fileName)
+			if err := os.WriteFile(path, []byte(tC.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			policy, err := LoadPolicy(path)
+			if err != nil {
+				t.Fatal("shouldn't have returned an error, got", err)
+			}
+			if policy.MaxExpiration != expected.MaxExpiration || policy.RequiredTitlePrefix != expected.RequiredTitlePrefix || policy.Mode != expected.Mode {
+				t.Errorf("expected %+v, got %+v", expected, *policy)
+			}
+			if len(policy.AllowedVisibilities) != 2 || policy.AllowedVisibilities[0] != VisibilityUnlisted || policy.AllowedVisibilities[1] != VisibilityPrivate {
+				t.Errorf("expected allowed visibilities %v, got %v", expected.AllowedVisibilities, policy.AllowedVisibilities)
+			}
+			if len(policy.AllowedSyntaxes) != 2 || policy.AllowedSyntaxes[0] != "text" || policy.AllowedSyntaxes[1] != "go" {
+				t.Errorf("expected allowed syntaxes %v, got %v", expected.AllowedSyntaxes, policy.AllowedSyntaxes)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyWithInvalidPolicy(t *testing.T) {
+	testCases := []struct {
+		fileName string
+		content  string
+	}{
+		{fileName: "unknown-visibility.json", content: `{"allowed_visibilities": ["secret"]}`},
+		{fileName: "unknown-expiration.yaml", content: "max_expiration: 3W"},
+		{fileName: "unknown-mode.yaml", content: "mode: ignore"},
+		{fileName: "unknown-field.json", content: `{"max_size": 10}`},
+		{fileName: "nested-mapping.yaml", content: "mode:\n  value: reject"},
+		{fileName: "unsupported-extension.toml", content: `mode = "reject"`},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.fileName, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tC.fileName)
+			if err := os.WriteFile(path, []byte(tC.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadPolicy(path); err == nil {
+				t.Error("should've returned an error")
+			}
+		})
+	}
+}
+
+func TestParsePolicyYAMLWithEmptyValue(t *testing.T) {
+	policy, err := ParsePolicyYAML([]byte("required_title_prefix:\nallowed_syntaxes:\nmax_expiration: 1W\n"))
+	if err != nil {
+		t.Fatal("an empty value should be treated as null, got", err)
+	}
+	if len(policy.RequiredTitlePrefix) != 0 || len(policy.AllowedSyntaxes) != 0 || policy.MaxExpiration != ExpirationOneWeek {
+		t.Errorf("unexpected policy %+v", policy)
+	}
+}
+
+func TestPolicy_JSON(t *testing.T) {
+	policy := Policy{AllowedVisibilities: []Visibility{VisibilityUnlisted, VisibilityPrivate}, Mode: PolicyModeRewrite}
+	data, err := json.Marshal(policy)
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if expected := `{"allowed_visibilities":["unlisted","private"],"mode":"rewrite"}`; string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+	var decoded Policy
+	if err = json.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded, policy) {
+		t.Errorf("expected %+v, got %+v (%v)", policy, decoded, err)
+	}
+	// Visibility itself must keep being encoded as a number
+	data, _ = json.Marshal(CreatePasteRequest{Visibility: VisibilityPrivate})
+	if !strings.Contains(string(data), `"Visibility":2`) {
+		t.Errorf("expected the visibility of the request to be encoded as a number, got %s", data)
+	}
+}
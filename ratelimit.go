@@ -0,0 +1,52 @@
+package pastebin
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter spaces requests by a minimum interval
+type rateLimiter struct {
+	interval time.Duration
+	next     time.Time
+	mutex    sync.Mutex
+}
+
+// reserve reserves the next available slot and returns how long the caller must wait before using it
+func (rl *rateLimiter) reserve() time.Duration {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	now := time.Now()
+	if rl.next.Before(now) {
+		rl.next = now
+	}
+	wait := rl.next.Sub(now)
+	rl.next = rl.next.Add(rl.interval)
+	return wait
+}
+
+// WithRateLimit configures the client to wait at least the given interval between the start of two requests,
+// which helps staying under Pastebin's rate limits. An interval of 0 or less disables the rate limit.
+//
+// Returns the client to allow chaining
+func (c *Client) WithRateLimit(interval time.Duration) *Client {
+	if interval <= 0 {
+		c.rateLimiter = nil
+	} else {
+		c.rateLimiter = &rateLimiter{interval: interval}
+	}
+	return c
+}
+
+// waitForRateLimit blocks until the rate limit of the client allows a request to be sent
+func (c *Client) waitForRateLimit(operation Operation) {
+	if c.rateLimiter == nil {
+		return
+	}
+	if wait := c.rateLimiter.reserve(); wait > 0 {
+		c.notify(func(observer Observer) {
+			observer.OnRateLimitWait(&RateLimitWaitEvent{Operation: operation, Wait: wait})
+		})
+		time.Sleep(wait)
+	}
+}
@@ -0,0 +1,36 @@
+package pastebin
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func TestClient_WithRateLimit(t *testing.T) {
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("this is code"))}
+	})}
+	var waits []time.Duration
+	client, _ := NewClient("", "", "token")
+	client.WithRateLimit(50 * time.Millisecond).WithObserver(&Hooks{
+		RateLimitWait: func(event *RateLimitWaitEvent) {
+			waits = append(waits, event.Wait)
+		},
+	})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetPasteContent("abcdefgh"); err != nil {
+			t.Fatal("shouldn't have returned an error, got", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("3 requests with a rate limit of 1 request per 50ms should've taken at least 100ms, took %s", elapsed)
+	}
+	if len(waits) != 2 {
+		t.Errorf("expected %d rate limit waits, got %d", 2, len(waits))
+	}
+}
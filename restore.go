@@ -0,0 +1,154 @@
+package pastebin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidBackupArchive = errors.New("invalid backup archive")
+)
+
+// RestoreOptions are the options of RestoreArchive
+type RestoreOptions struct {
+	// Concurrency is the maximum number of pastes created at the same time. Defaults to DefaultConcurrency.
+	Concurrency int
+
+	// MappingFile, if set, is the path of the JSON file to which the mapping of the old keys to the new keys is
+	// written every time a paste is created. If the file already exists, the pastes it contains are not restored again,
+	// which allows a restore that partially failed or was interrupted to be resumed.
+	MappingFile string
+}
+
+// RestoreResult is the result of RestoreArchive
+type RestoreResult struct {
+	// Mapping maps the keys of the pastes in the archive to the keys of the pastes that were created
+	Mapping map[string]string
+
+	// Expired are the keys of the pastes that were skipped because they had already expired
+	Expired []string
+}
+
+// RestoreArchive recreates the pastes of an archive written by Backup with CreatePasteWithResult, preserving their
+// title, syntax and visibility. The format of the archive is detected automatically.
+//
+// The content of every paste is verified against the checksum in the manifest before anything is created.
+// Pastes that expire are created with the Expiration closest to the time they had left before expiring, and pastes
+// that have already expired are skipped. Note that private pastes can only be restored by an authenticated client.
+//
+// Every paste is attempted even if some fail, in which case the result is returned along with a *BulkError.
+func (c *Client) RestoreArchive(r io.Reader, options RestoreOptions) (*RestoreResult, error) {
+	manifest, contents, err := readBackupArchive(r)
+	if err != nil {
+		return nil, err
+	}
+	result := &RestoreResult{Mapping: make(map[string]string)}
+	if len(options.MappingFile) > 0 {
+		if result.Mapping, err = readRestoreMapping(options.MappingFile); err != nil {
+			return nil, err
+		}
+	}
+	now := time.Now()
+	var pasteKeys []string
+	for _, paste := range manifest.Pastes {
+		if _, restored := result.Mapping[paste.Key]; restored {
+			continue
+		}
+		if paste.ExpireDate.Unix() > 0 && !paste.ExpireDate.After(now) {
+			result.Expired = append(result.Expired, paste.Key)
+			continue
+		}
+		pasteKeys = append(pasteKeys, paste.Key)
+	}
+	pastesByKey := make(map[string]*BackupPaste, len(manifest.Pastes))
+	for _, paste := range manifest.Pastes {
+		pastesByKey[paste.Key] = paste
+	}
+	var mutex sync.Mutex
+	_, restoreErr := runBulk(pasteKeys, options.Concurrency, func(pasteKey string) (string, error) {
+		paste := pastesByKey[pasteKey]
+		created, err := c.CreatePasteWithResult(NewCreatePasteRequest(paste.Title, contents[pasteKey], remainingExpiration(paste.ExpireDate, now), paste.Visibility, paste.Syntax))
+		if err != nil {
+			return "", err
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		result.Mapping[pasteKey] = created.Key
+		// The mapping is written as soon as a paste is created, so that it's never restored twice even if the restore
+		// is interrupted
+		if len(options.MappingFile) > 0 {
+			if err = writeRestoreMapping(options.MappingFile, result.Mapping); err != nil {
+				return created.Key, fmt.Errorf("created %s but failed to write the mapping file: %w", created.Key, err)
+			}
+		}
+		return created.Key, nil
+	})
+	return result, restoreErr
+}
+
+// readBackupArchive reads the manifest of an archive written by Backup and the content of every paste it lists,
+// verifying their checksums
+func readBackupArchive(r io.Reader) (*BackupManifest, map[string]string, error) {
+	files, err := readArchiveFiles(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidBackupArchive, err.Error())
+	}
+	manifestData, exists := files[BackupManifestFileName]
+	if !exists {
+		return nil, nil, fmt.Errorf("%w: missing %s", ErrInvalidBackupArchive, BackupManifestFileName)
+	}
+	var manifest BackupManifest
+	if err = json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidBackupArchive, err.Error())
+	}
+	if manifest.Format != backupManifestFormat || manifest.Version != backupManifestVersion {
+		return nil, nil, fmt.Errorf("%w: unsupported manifest format %q version %d", ErrInvalidBackupArchive, manifest.Format, manifest.Version)
+	}
+	contents := make(map[string]string, len(manifest.Pastes))
+	for _, paste := range manifest.Pastes {
+		content, exists := files[paste.File]
+		if !exists {
+			return nil, nil, fmt.Errorf("%w: missing %s for paste %s", ErrInvalidBackupArchive, paste.File, paste.Key)
+		}
+		if checksum := sha256.Sum256(content); hex.EncodeToString(checksum[:]) != paste.SHA256 {
+			return nil, nil, fmt.Errorf("%w: checksum mismatch for paste %s", ErrInvalidBackupArchive, paste.Key)
+		}
+		contents[paste.Key] = string(content)
+	}
+	return &manifest, contents, nil
+}
+
+// readRestoreMapping reads the mapping file of a previous restore, if it exists
+func readRestoreMapping(path string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return mapping, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("invalid mapping file %s: %w", path, err)
+	}
+	if mapping == nil {
+		mapping = make(map[string]string)
+	}
+	return mapping, nil
+}
+
+func writeRestoreMapping(path string, mapping map[string]string) error {
+	data, err := json.MarshalIndent(mapping, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(path, data)
+}
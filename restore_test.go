@@ -0,0 +1,140 @@
+package pastebin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func newTestBackupArchive(t *testing.T, format ArchiveFormat, pastes map[*Paste]string) []byte {
+	var buffer bytes.Buffer
+	archive, err := newArchiveWriter(&buffer, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := &BackupManifest{Format: backupManifestFormat, Version: backupManifestVersion}
+	for paste, content := range pastes {
+		manifest.Pastes = append(manifest.Pastes, newBackupPaste(paste, content))
+	}
+	manifestData, _ := json.Marshal(manifest)
+	_ = archive.writeFile(BackupManifestFileName, manifestData, time.Now())
+	for paste, content := range pastes {
+		_ = archive.writeFile(backupPastesDirectory+paste.Key+".txt", []byte(content), time.Now())
+	}
+	_ = archive.Close()
+	return buffer.Bytes()
+}
+
+func TestClient_RestoreArchive(t *testing.T) {
+	pastes := map[*Paste]string{
+		{Key: "never001", Title: "never", Syntax: "go", Visibility: VisibilityPrivate, ExpireDate: time.Unix(0, 0)}:                       "content 1",
+		{Key: "expires1", Title: "expires", Syntax: "text", Visibility: VisibilityUnlisted, ExpireDate: time.Now().Add(50 * time.Minute)}: "content 2",
+		{Key: "expired1", Title: "expired", Visibility: VisibilityPublic, ExpireDate: time.Now().Add(-time.Minute)}:                       "content 3",
+	}
+	for _, format := range []ArchiveFormat{ArchiveFormatTar, ArchiveFormatZip} {
+		t.Run(string(format), func(t *testing.T) {
+			account := newMockAccount()
+			httpClient = account.httpClient()
+			client, _ := NewClient("username", "password", "token")
+			mappingFile := filepath.Join(t.TempDir(), "mapping.json")
+			result, err := client.RestoreArchive(bytes.NewReader(newTestBackupArchive(t, format, pastes)), RestoreOptions{MappingFile: mappingFile})
+			if err != nil {
+				t.Fatal("shouldn't have returned an error, got", err)
+			}
+			if len(result.Expired) != 1 || result.Expired[0] != "expired1" {
+				t.Errorf("expected expired1 to be skipped, got %v", result.Expired)
+			}
+			if len(result.Mapping) != 2 || len(account.pastes) != 2 {
+				t.Fatalf("expected 2 pastes to be restored, got %v", result.Mapping)
+			}
+			never := account.pastes[result.Mapping["never001"]]
+			if never.title != "never" || never.content != "content 1" || never.syntax != "go" || never.visibility != int(VisibilityPrivate) || never.expiration != string(ExpirationNever) {
+				t.Errorf("unexpected restored paste %+v", never)
+			}
+			if expires := account.pastes[result.Mapping["expires1"]]; expires.expiration != string(ExpirationOneHour) || expires.visibility != int(VisibilityUnlisted) {
+				t.Errorf("unexpected restored paste %+v", expires)
+			}
+			data, err := os.ReadFile(mappingFile)
+			if err != nil {
+				t.Fatal("mapping file should've been written, got", err)
+			}
+			var mapping map[string]string
+			if err = json.Unmarshal(data, &mapping); err != nil || mapping["never001"] != result.Mapping["never001"] {
+				t.Errorf("unexpected mapping file %s", data)
+			}
+			// Restoring again with the same mapping file should not create the pastes again
+			if _, err = client.RestoreArchive(bytes.NewReader(newTestBackupArchive(t, format, pastes)), RestoreOptions{MappingFile: mappingFile}); err != nil {
+				t.Fatal("shouldn't have returned an error, got", err)
+			}
+			if len(account.pastes) != 2 {
+				t.Errorf("expected pastes in the mapping file not to be restored again, got %d pastes", len(account.pastes))
+			}
+		})
+	}
+}
+
+func TestClient_RestoreArchiveWithInvalidArchive(t *testing.T) {
+	client, _ := NewClient("", "", "token")
+	if _, err := client.RestoreArchive(bytes.NewReader([]byte("not an archive")), RestoreOptions{}); !errors.Is(err, ErrInvalidBackupArchive) {
+		t.Errorf("expected %v, got %v", ErrInvalidBackupArchive, err)
+	}
+	var buffer bytes.Buffer
+	archive, _ := newArchiveWriter(&buffer, ArchiveFormatTar)
+	manifest, _ := json.Marshal(&BackupManifest{Format: backupManifestFormat, Version: backupManifestVersion, Pastes: []*BackupPaste{newBackupPaste(&Paste{Key: "abcdefgh"}, "content")}})
+	_ = archive.writeFile(BackupManifestFileName, manifest, time.Now())
+	_ = archive.writeFile(backupPastesDirectory+"abcdefgh.txt", []byte("tampered"), time.Now())
+	_ = archive.Close()
+	if _, err := client.RestoreArchive(&buffer, RestoreOptions{}); !errors.Is(err, ErrInvalidBackupArchive) {
+		t.Errorf("expected %v, got %v", ErrInvalidBackupArchive, err)
+	}
+}
+
+func TestClient_RestoreArchiveWithFailures(t *testing.T) {
+	account := newMockAccount()
+	httpClient = account.httpClient()
+	client, _ := NewClient("", "", "token")
+	pastes := map[*Paste]string{
+		{Key: "public01", Title: "public", ExpireDate: time.Unix(0, 0)}:                                 "content 1",
+		{Key: "private1", Title: "private", Visibility: VisibilityPrivate, ExpireDate: time.Unix(0, 0)}: "content 2",
+	}
+	result, err := client.RestoreArchive(bytes.NewReader(newTestBackupArchive(t, ArchiveFormatTar, pastes)), RestoreOptions{})
+	var bulkErr *BulkError
+	if !errors.As(err, &bulkErr) || !errors.Is(err, ErrNotAuthenticated) {
+		t.Fatalf("expected a *BulkError caused by the private paste, got %v", err)
+	}
+	if len(result.Mapping) != 1 || len(result.Mapping["public01"]) == 0 {
+		t.Errorf("expected the public paste to be restored, got %v", result.Mapping)
+	}
+}
+
+func TestClient_RestoreArchiveWritesMappingAfterEachPaste(t *testing.T) {
+	account := newMockAccount()
+	mappingFile := filepath.Join(t.TempDir(), "mapping.json")
+	var mappingSizes []int
+	mockHttpClient := account.httpClient()
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		// Record the size of the mapping file every time a paste is about to be created
+		mapping, _ := readRestoreMapping(mappingFile)
+		mappingSizes = append(mappingSizes, len(mapping))
+		response, _ := mockHttpClient.Transport.RoundTrip(request)
+		return response
+	})}
+	client, _ := NewClient("", "", "token")
+	pastes := map[*Paste]string{
+		{Key: "public01", Title: "first", ExpireDate: time.Unix(0, 0)}:  "content 1",
+		{Key: "public02", Title: "second", ExpireDate: time.Unix(0, 0)}: "content 2",
+	}
+	if _, err := client.RestoreArchive(bytes.NewReader(newTestBackupArchive(t, ArchiveFormatTar, pastes)), RestoreOptions{Concurrency: 1, MappingFile: mappingFile}); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if len(mappingSizes) != 2 || mappingSizes[0] != 0 || mappingSizes[1] != 1 {
+		t.Errorf("expected the mapping file to contain the first paste before the second was created, got sizes %v", mappingSizes)
+	}
+}
@@ -0,0 +1,85 @@
+package pastebin
+
+import (
+	"time"
+)
+
+// CreatePasteResult is the result of CreatePasteWithResult
+type CreatePasteResult struct {
+	// Key is the key of the paste that was created
+	Key string
+
+	// URL is the canonical URL of the paste (e.g. https://pastebin.com/abcdefgh), which uses the base URL configured
+	// with WithBaseURL
+	URL string
+
+	// RawURL is the URL of the raw content of the paste
+	RawURL string
+
+	// EmbedURL is the URL that can be used to embed the paste in an iframe
+	EmbedURL string
+
+	// DownloadURL is the URL that can be used to download the paste as a file
+	DownloadURL string
+
+	// Title, Syntax, Expiration and Visibility are the values the paste was created with, which may differ from those
+	// of the CreatePasteRequest if the request was rewritten by the policy configured with WithPolicy
+	Title      string
+	Syntax     string
+	Expiration Expiration
+	Visibility Visibility
+
+	// Size is the number of bytes of content that was sent, which may differ from the size of the content of the
+	// CreatePasteRequest if it was redacted by the scanner configured with WithContentScanner
+	Size int
+
+	// Date is the time at which the paste was created, as observed by the client
+	Date time.Time
+
+	// ExpireDate is the time at which the paste will expire, computed from Date and Expiration.
+	// It is the zero value if the paste never expires.
+	ExpireDate time.Time
+
+	// Duration is the time it took to create the paste, including the time spent waiting for the rate limit and
+	// re-authenticating if the session key was no longer valid
+	Duration time.Duration
+
+	// content is the content that was sent, after being passed through the scanner configured with WithContentScanner
+	content string
+}
+
+func newCreatePasteResult(urlBuilder *URLBuilder, key, title, syntax string, expiration Expiration, visibility Visibility, size int, startedAt time.Time) *CreatePasteResult {
+	now := time.Now()
+	result := &CreatePasteResult{
+		Key:         key,
+		URL:         urlBuilder.ViewURL(key),
+		RawURL:      urlBuilder.RawURL(key),
+		EmbedURL:    urlBuilder.EmbedURL(key),
+		DownloadURL: urlBuilder.DownloadURL(key),
+		Title:       title,
+		Syntax:      syntax,
+		Expiration:  expiration,
+		Visibility:  visibility,
+		Size:        size,
+		Date:        now,
+		Duration:    now.Sub(startedAt),
+	}
+	if duration := expiration.Duration(); duration > 0 {
+		result.ExpireDate = now.Add(duration)
+	}
+	return result
+}
+
+// ToPaste converts the result to a Paste. Note that User and Hits are not set.
+func (r *CreatePasteResult) ToPaste() *Paste {
+	return &Paste{
+		Key:        r.Key,
+		Title:      r.Title,
+		URL:        r.URL,
+		Size:       r.Size,
+		Date:       r.Date,
+		ExpireDate: r.ExpireDate,
+		Visibility: r.Visibility,
+		Syntax:     r.Syntax,
+	}
+}
@@ -0,0 +1,64 @@
+package pastebin
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func TestClient_CreatePasteWithResult(t *testing.T) {
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("https://pastebin.com/abcdefgh"))}
+	})}
+	client, _ := NewClient("", "", "token")
+	client.WithPolicy(&Policy{AllowedVisibilities: []Visibility{VisibilityUnlisted}, Mode: PolicyModeRewrite})
+	before := time.Now()
+	result, err := client.CreatePasteWithResult(NewCreatePasteRequest("title", "content", ExpirationOneDay, VisibilityPublic, "go"))
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	expectedURLs := map[string]string{
+		"https://pastebin.com/abcdefgh":              result.URL,
+		"https://pastebin.com/raw/abcdefgh":          result.RawURL,
+		"https://pastebin.com/embed_iframe/abcdefgh": result.EmbedURL,
+		"https://pastebin.com/dl/abcdefgh":           result.DownloadURL,
+	}
+	for expected, actual := range expectedURLs {
+		if expected != actual {
+			t.Errorf("expected %s, got %s", expected, actual)
+		}
+	}
+	if result.Key != "abcdefgh" || result.Title != "title" || result.Syntax != "go" || result.Size != len("content") {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if result.Visibility != VisibilityUnlisted {
+		t.Errorf("expected the visibility that was sent, %s, got %s", VisibilityUnlisted, result.Visibility)
+	}
+	if result.Date.Before(before) || result.Duration <= 0 {
+		t.Errorf("unexpected date %s or duration %s", result.Date, result.Duration)
+	}
+	if expected := result.Date.Add(24 * time.Hour); !result.ExpireDate.Equal(expected) {
+		t.Errorf("expected expire date %s, got %s", expected, result.ExpireDate)
+	}
+	if paste := result.ToPaste(); paste.Key != result.Key || paste.URL != result.URL || paste.Visibility != result.Visibility {
+		t.Errorf("unexpected paste %+v", paste)
+	}
+}
+
+func TestClient_CreatePasteWithResultWithoutExpiration(t *testing.T) {
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString("https://pastebin.com/abcdefgh"))}
+	})}
+	client, _ := NewClient("", "", "token")
+	result, err := client.CreatePasteWithResult(NewCreatePasteRequest("", "content", "", VisibilityPublic, ""))
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if result.Expiration != ExpirationNever || !result.ExpireDate.IsZero() {
+		t.Errorf("expected a paste that never expires, got expiration %s and expire date %s", result.Expiration, result.ExpireDate)
+	}
+}
@@ -0,0 +1,303 @@
+package pastebin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RetentionRule selects the pastes to delete. A paste matches a rule if it meets every condition of the rule.
+type RetentionRule struct {
+	// Name identifies the rule in RetentionReport
+	Name string `json:"name,omitempty"`
+
+	// OlderThanDays matches the pastes created more than the given number of days ago
+	OlderThanDays int `json:"older_than_days,omitempty"`
+
+	// ZeroHits matches the pastes that were never viewed
+	ZeroHits bool `json:"zero_hits,omitempty"`
+
+	// TitlePattern is a regular expression matching the title of the pastes
+	TitlePattern string `json:"title_pattern,omitempty"`
+
+	// MaxPerSyntax matches the pastes beyond the given number of most recent pastes with the same syntax, among the
+	// pastes meeting the other conditions of the rule
+	MaxPerSyntax int `json:"max_per_syntax,omitempty"`
+
+	titleRegexp *regexp.Regexp
+}
+
+// RetentionPolicy is a set of rules selecting the pastes to delete. A paste is deleted if it matches any rule and is
+// not protected.
+type RetentionPolicy struct {
+	Rules []*RetentionRule `json:"rules"`
+
+	// ProtectedKeys are the keys of the pastes that must never be deleted
+	ProtectedKeys []string `json:"protected_keys,omitempty"`
+
+	// ProtectedTitlePatterns are regular expressions matching the title of the pastes that must never be deleted
+	ProtectedTitlePatterns []string `json:"protected_title_patterns,omitempty"`
+
+	protectedTitleRegexps []*regexp.Regexp
+}
+
+// Validate returns an error if the policy is invalid, and compiles its regular expressions
+func (p *RetentionPolicy) Validate() error {
+	var errs []error
+	for i, rule := range p.Rules {
+		name := rule.name(i)
+		if rule.OlderThanDays < 0 || rule.MaxPerSyntax < 0 {
+			errs = append(errs, fmt.Errorf("%s: older_than_days and max_per_syntax must not be negative", name))
+		}
+		if rule.OlderThanDays == 0 && !rule.ZeroHits && len(rule.TitlePattern) == 0 && rule.MaxPerSyntax == 0 {
+			// A rule without conditions would match every paste
+			errs = append(errs, fmt.Errorf("%s: must have at least one condition", name))
+		}
+		if len(rule.TitlePattern) > 0 {
+			var err error
+			if rule.titleRegexp, err = regexp.Compile(rule.TitlePattern); err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid title_pattern: %w", name, err))
+			}
+		}
+	}
+	p.protectedTitleRegexps = nil
+	for _, pattern := range p.ProtectedTitlePatterns {
+		protectedTitleRegexp, err := regexp.Compile(pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid protected_title_patterns: %w", err))
+			continue
+		}
+		p.protectedTitleRegexps = append(p.protectedTitleRegexps, protectedTitleRegexp)
+	}
+	return errors.Join(errs...)
+}
+
+// name returns the name of the rule, or its position in the policy if it has no name
+func (r *RetentionRule) name(index int) string {
+	if len(r.Name) > 0 {
+		return r.Name
+	}
+	return fmt.Sprintf("rule %d", index+1)
+}
+
+// LoadRetentionPolicy reads a retention policy from a JSON file
+func LoadRetentionPolicy(path string) (*RetentionPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRetentionPolicyJSON(data)
+}
+
+// ParseRetentionPolicyJSON parses and validates a retention policy in the JSON format, e.g.
+//
+//	{
+//	  "rules": [
+//	    {"name": "stale-debug", "title_pattern": "^debug", "older_than_days": 7},
+//	    {"name": "unread", "zero_hits": true, "older_than_days": 30},
+//	    {"name": "logs", "max_per_syntax": 20, "title_pattern": "\\.log$"}
+//	  ],
+//	  "protected_keys": ["abcdefgh"],
+//	  "protected_title_patterns": ["(?i)runbook"]
+//	}
+func ParseRetentionPolicyJSON(data []byte) (*RetentionPolicy, error) {
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	policy := &RetentionPolicy{}
+	if err := decoder.Decode(policy); err != nil {
+		return nil, err
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// RetentionMatch is a paste matched by a rule of a RetentionPolicy
+type RetentionMatch struct {
+	Paste *Paste
+
+	// Rule is the name of the first rule the paste matched
+	Rule string
+
+	// Err is the error that prevented the paste from being deleted, if any
+	Err error
+}
+
+// RetentionReport is the result of ApplyRetentionPolicy
+type RetentionReport struct {
+	// Deleted are the pastes that were deleted, or that would be deleted in a dry run
+	Deleted []*RetentionMatch
+
+	// Protected are the pastes that matched a rule, but were not deleted because they are protected
+	Protected []*RetentionMatch
+
+	// DryRun is whether the pastes were left untouched
+	DryRun bool
+
+	// Truncated is whether the user has at least MaxUserPastes pastes, in which case only the most recent ones were
+	// evaluated, since Pastebin doesn't list the others
+	Truncated bool
+}
+
+// String returns the pastes of the report, one per line
+func (r *RetentionReport) String() string {
+	var output strings.Builder
+	verb := "delete"
+	if r.DryRun {
+		verb = "would delete"
+	}
+	for _, match := range r.Deleted {
+		status := verb
+		if match.Err != nil {
+			status = "failed to delete"
+		}
+		fmt.Fprintf(&output, "%s %s %q (%s)", status, match.Paste.Key, match.Paste.Title, match.Rule)
+		if match.Err != nil {
+			fmt.Fprintf(&output, ": %v", match.Err)
+		}
+		output.WriteByte('\n')
+	}
+	for _, match := range r.Protected {
+		fmt.Fprintf(&output, "protected %s %q (%s)\n", match.Paste.Key, match.Paste.Title, match.Rule)
+	}
+	if output.Len() == 0 {
+		output.WriteString("nothing to delete\n")
+	}
+	if r.Truncated {
+		fmt.Fprintf(&output, "warning: only the %d most recent pastes were evaluated, older pastes may match as well\n", MaxUserPastes)
+	}
+	return output.String()
+}
+
+// RetentionOptions are the options of ApplyRetentionPolicy
+type RetentionOptions struct {
+	// DryRun only reports the pastes that would be deleted, without deleting them
+	DryRun bool
+
+	// Concurrency is the maximum number of pastes deleted at the same time. Defaults to DefaultConcurrency.
+	Concurrency int
+}
+
+// ApplyRetentionPolicy evaluates a retention policy against the MaxUserPastes most recent pastes of the user, and
+// deletes the pastes matching any rule with DeletePaste, unless options.DryRun is true.
+//
+// Since Pastebin lists at most MaxUserPastes pastes, older pastes cannot be evaluated if the user has more than that,
+// which is reported by RetentionReport.Truncated. Applying the policy again once the matching pastes are deleted
+// evaluates the older pastes that are now listed.
+//
+// Pastes listed in the protection lists of the policy are never deleted, nor are the pastes that an alias points to if
+// the client has an AliasRegistry (see WithAliases). Every paste is attempted even if some fail, in which case the
+// report is returned along with a *BulkError.
+func (c *Client) ApplyRetentionPolicy(policy *RetentionPolicy, options RetentionOptions) (*RetentionReport, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	pastes, truncated, err := c.getAllUserPastes()
+	if err != nil {
+		return nil, err
+	}
+	protectedKeys := make(map[string]bool)
+	for _, pasteKey := range policy.ProtectedKeys {
+		protectedKeys[pasteKey] = true
+	}
+	if c.aliases != nil {
+		aliases, err := c.aliases.Aliases()
+		if err != nil {
+			return nil, err
+		}
+		for _, pasteKey := range aliases {
+			protectedKeys[pasteKey] = true
+		}
+	}
+	report := policy.evaluate(pastes, protectedKeys, time.Now())
+	report.DryRun = options.DryRun
+	report.Truncated = truncated
+	if options.DryRun || len(report.Deleted) == 0 {
+		return report, nil
+	}
+	pasteKeys := make([]string, 0, len(report.Deleted))
+	pastesByKey := make(map[string]*Paste, len(report.Deleted))
+	for _, match := range report.Deleted {
+		pasteKeys = append(pasteKeys, match.Paste.Key)
+		pastesByKey[match.Paste.Key] = match.Paste
+	}
+	errByPasteKey, err := c.deletePastes(pasteKeys, options.Concurrency, pastesByKey)
+	for _, match := range report.Deleted {
+		match.Err = errByPasteKey[match.Paste.Key]
+	}
+	return report, err
+}
+
+// evaluate returns the pastes matching the rules of the policy
+func (p *RetentionPolicy) evaluate(pastes []*Paste, protectedKeys map[string]bool, now time.Time) *RetentionReport {
+	ruleByPasteKey := make(map[string]string)
+	for i, rule := range p.Rules {
+		for _, paste := range rule.match(pastes, now) {
+			if _, matched := ruleByPasteKey[paste.Key]; !matched {
+				ruleByPasteKey[paste.Key] = rule.name(i)
+			}
+		}
+	}
+	report := &RetentionReport{}
+	for _, paste := range pastes {
+		rule, matched := ruleByPasteKey[paste.Key]
+		if !matched {
+			continue
+		}
+		match := &RetentionMatch{Paste: paste, Rule: rule}
+		if protectedKeys[paste.Key] || p.isProtectedTitle(paste.Title) {
+			report.Protected = append(report.Protected, match)
+		} else {
+			report.Deleted = append(report.Deleted, match)
+		}
+	}
+	return report
+}
+
+func (p *RetentionPolicy) isProtectedTitle(title string) bool {
+	for _, protectedTitleRegexp := range p.protectedTitleRegexps {
+		if protectedTitleRegexp.MatchString(title) {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns the pastes meeting every condition of the rule
+func (r *RetentionRule) match(pastes []*Paste, now time.Time) []*Paste {
+	var matches []*Paste
+	for _, paste := range pastes {
+		if r.OlderThanDays > 0 && !paste.Date.Before(now.AddDate(0, 0, -r.OlderThanDays)) {
+			continue
+		}
+		if r.ZeroHits && paste.Hits > 0 {
+			continue
+		}
+		if r.titleRegexp != nil && !r.titleRegexp.MatchString(paste.Title) {
+			continue
+		}
+		matches = append(matches, paste)
+	}
+	if r.MaxPerSyntax == 0 {
+		return matches
+	}
+	// Only the pastes beyond the most recent MaxPerSyntax pastes of each syntax match
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Date.After(matches[j].Date)
+	})
+	countBySyntax := make(map[string]int)
+	var beyondCap []*Paste
+	for _, paste := range matches {
+		countBySyntax[paste.Syntax]++
+		if countBySyntax[paste.Syntax] > r.MaxPerSyntax {
+			beyondCap = append(beyondCap, paste)
+		}
+	}
+	return beyondCap
+}
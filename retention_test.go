@@ -0,0 +1,128 @@
+package pastebin
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicy_evaluate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time {
+		return now.AddDate(0, 0, -days)
+	}
+	pastes := []*Paste{
+		{Key: "debug001", Title: "debug output", Date: daysAgo(10), Hits: 5, Syntax: "text"},
+		{Key: "debug002", Title: "debug output", Date: daysAgo(2), Hits: 0, Syntax: "text"},
+		{Key: "unread01", Title: "notes", Date: daysAgo(40), Hits: 0, Syntax: "text"},
+		{Key: "runbook1", Title: "Runbook", Date: daysAgo(400), Hits: 0, Syntax: "markdown"},
+		{Key: "log00001", Title: "a.log", Date: daysAgo(1), Syntax: "text"},
+		{Key: "log00002", Title: "b.log", Date: daysAgo(2), Syntax: "text"},
+		{Key: "log00003", Title: "c.log", Date: daysAgo(3), Syntax: "text"},
+		{Key: "log00004", Title: "d.log", Date: daysAgo(4), Syntax: "go"},
+		{Key: "protect1", Title: "e.log", Date: daysAgo(5), Syntax: "text"},
+	}
+	policy := &RetentionPolicy{
+		Rules: []*RetentionRule{
+			{Name: "stale-debug", TitlePattern: "^debug", OlderThanDays: 7},
+			{Name: "unread", ZeroHits: true, OlderThanDays: 30},
+			{MaxPerSyntax: 2, TitlePattern: `\.log$`},
+		},
+		ProtectedKeys:          []string{"protect1"},
+		ProtectedTitlePatterns: []string{"(?i)runbook"},
+	}
+	if err := policy.Validate(); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	report := policy.evaluate(pastes, map[string]bool{"protect1": true}, now)
+	var deleted, protected []string
+	for _, match := range report.Deleted {
+		deleted = append(deleted, match.Paste.Key+":"+match.Rule)
+	}
+	for _, match := range report.Protected {
+		protected = append(protected, match.Paste.Key+":"+match.Rule)
+	}
+	if expected := "debug001:stale-debug,unread01:unread,log00003:rule 3"; strings.Join(deleted, ",") != expected {
+		t.Errorf("expected %s to be deleted, got %s", expected, strings.Join(deleted, ","))
+	}
+	if expected := "runbook1:unread,protect1:rule 3"; strings.Join(protected, ",") != expected {
+		t.Errorf("expected %s to be protected, got %s", expected, strings.Join(protected, ","))
+	}
+}
+
+func TestParseRetentionPolicyJSON(t *testing.T) {
+	policy, err := ParseRetentionPolicyJSON([]byte(`{"rules": [{"name": "old", "older_than_days": 90}], "protected_keys": ["abcdefgh"]}`))
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if len(policy.Rules) != 1 || policy.Rules[0].OlderThanDays != 90 || policy.ProtectedKeys[0] != "abcdefgh" {
+		t.Errorf("unexpected policy %+v", policy)
+	}
+	for _, invalid := range []string{
+		`{"rules": [{"name": "everything"}]}`,
+		`{"rules": [{"older_than_days": -1}]}`,
+		`{"rules": [{"title_pattern": "("}]}`,
+		`{"rules": [{"zero_hits": true}], "protected_title_patterns": ["("]}`,
+		`{"rules": [{"unknown_field": true}]}`,
+	} {
+		if _, err = ParseRetentionPolicyJSON([]byte(invalid)); err == nil {
+			t.Errorf("expected an error for %s", invalid)
+		}
+	}
+}
+
+func TestClient_ApplyRetentionPolicy(t *testing.T) {
+	account := newMockAccount()
+	old := time.Now().AddDate(0, 0, -30).Unix()
+	account.pastes["paste001"] = &mockAccountPaste{title: "debug 1", content: "1", date: old}
+	account.pastes["paste002"] = &mockAccountPaste{title: "debug 2", content: "2", date: time.Now().Unix()}
+	account.pastes["paste003"] = &mockAccountPaste{title: "debug 3", content: "3", date: old}
+	account.pastes["paste004"] = &mockAccountPaste{title: "runbook", content: "4", date: old}
+	httpClient = account.httpClient()
+	registry := NewFileAliasRegistry(filepath.Join(t.TempDir(), "aliases.json"))
+	_ = registry.Set("debug", "paste003")
+	client, _ := NewClient("username", "password", "token")
+	client.WithAliases(registry)
+	policy := &RetentionPolicy{Rules: []*RetentionRule{{TitlePattern: "^debug", OlderThanDays: 7}}}
+	report, err := client.ApplyRetentionPolicy(policy, RetentionOptions{DryRun: true})
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if len(report.Deleted) != 1 || report.Deleted[0].Paste.Key != "paste001" || len(report.Protected) != 1 || report.Protected[0].Paste.Key != "paste003" {
+		t.Fatalf("unexpected report:\n%s", report)
+	}
+	if !strings.HasPrefix(report.String(), `would delete paste001 "debug 1" (rule 1)`) {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+	if len(account.pastes) != 4 {
+		t.Error("a dry run shouldn't have deleted any paste")
+	}
+	if _, err = client.ApplyRetentionPolicy(policy, RetentionOptions{}); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if _, exists := account.pastes["paste001"]; exists || len(account.pastes) != 3 {
+		t.Error("expected paste001 to have been deleted, and only paste001")
+	}
+}
+
+func TestClient_ApplyRetentionPolicyWithTruncatedList(t *testing.T) {
+	account := newMockAccount()
+	for i := 0; i < MaxUserPastes; i++ {
+		account.pastes[fmt.Sprintf("paste%04d", i)] = &mockAccountPaste{title: "recent", content: "content", date: time.Now().Unix()}
+	}
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	policy := &RetentionPolicy{Rules: []*RetentionRule{{OlderThanDays: 7}}}
+	report, err := client.ApplyRetentionPolicy(policy, RetentionOptions{DryRun: true})
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if !report.Truncated || len(report.Deleted) != 0 {
+		t.Fatalf("expected a truncated report with nothing to delete, got %+v", report)
+	}
+	if !strings.Contains(report.String(), "warning: only the 1000 most recent pastes were evaluated") {
+		t.Errorf("expected the report to warn about the truncated list, got:\n%s", report)
+	}
+}
@@ -0,0 +1,329 @@
+package pastebin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrContentRejected = errors.New("content rejected by scanner")
+)
+
+// ScanAction is what happens to content matched by a detector before a paste is created
+type ScanAction int
+
+const (
+	// ScanActionWarn allows the content, but logs a warning if a logger was configured with Client.WithLogger
+	ScanActionWarn ScanAction = iota
+
+	// ScanActionRedact replaces the matched content by [REDACTED:<detector name>]
+	ScanActionRedact
+
+	// ScanActionDeny prevents the paste from being created, returning a *ContentRejectedError
+	ScanActionDeny
+)
+
+func (a ScanAction) String() string {
+	switch a {
+	case ScanActionWarn:
+		return "warn"
+	case ScanActionRedact:
+		return "redact"
+	case ScanActionDeny:
+		return "deny"
+	default:
+		return "unknown"
+	}
+}
+
+// Finding is a match of a detector in the content of a paste
+type Finding struct {
+	// Detector is the name of the detector that matched
+	Detector string
+
+	// Action is the action configured for the detector
+	Action ScanAction
+
+	// Start and End are the byte offsets of the match in the content
+	Start, End int
+}
+
+// String returns a description of the finding, which does not include the matched content
+func (f Finding) String() string {
+	return fmt.Sprintf("%s at bytes %d-%d", f.Detector, f.Start, f.End)
+}
+
+// ContentRejectedError is returned by CreatePaste when a detector configured with ScanActionDeny matched the content,
+// or by CreateBinaryPaste when a detector configured with ScanActionDeny or ScanActionRedact matched the data
+type ContentRejectedError struct {
+	Findings []Finding
+}
+
+func (e *ContentRejectedError) Error() string {
+	descriptions := make([]string, 0, len(e.Findings))
+	for _, finding := range e.Findings {
+		descriptions = append(descriptions, finding.String())
+	}
+	return fmt.Sprintf("%s: %s", ErrContentRejected.Error(), strings.Join(descriptions, ", "))
+}
+
+// Is allows errors.Is(err, ErrContentRejected) to match a *ContentRejectedError
+func (e *ContentRejectedError) Is(target error) bool {
+	return target == ErrContentRejected
+}
+
+// Scanner scans the content of a paste before it is created
+type Scanner interface {
+	Scan(content string) []Finding
+}
+
+// Detector finds sensitive data in content
+type Detector interface {
+	// Name returns the name of the detector, which is used in findings and redactions
+	Name() string
+
+	// Detect returns the byte offsets of each match as [start, end] pairs
+	Detect(content string) [][2]int
+}
+
+// RegexpDetector is a Detector that matches a regular expression
+type RegexpDetector struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// NewRegexpDetector creates a new RegexpDetector. Panics if the pattern is not a valid regular expression.
+func NewRegexpDetector(name, pattern string) *RegexpDetector {
+	return &RegexpDetector{name: name, pattern: regexp.MustCompile(pattern)}
+}
+
+func (d *RegexpDetector) Name() string {
+	return d.name
+}
+
+func (d *RegexpDetector) Detect(content string) [][2]int {
+	var matches [][2]int
+	for _, match := range d.pattern.FindAllStringIndex(content, -1) {
+		matches = append(matches, [2]int{match[0], match[1]})
+	}
+	return matches
+}
+
+// EntropyDetector is a Detector that matches long tokens whose Shannon entropy suggests that they are random,
+// such as API keys or passwords
+type EntropyDetector struct {
+	// MinLength is the minimum length of a token for it to be considered
+	MinLength int
+
+	// Threshold is the minimum entropy, in bits per character, for a token to be matched
+	Threshold float64
+}
+
+var entropyTokenPattern = regexp.MustCompile(`[A-Za-z0-9+/=_\-]+`)
+
+func (d *EntropyDetector) Name() string {
+	return "high_entropy_string"
+}
+
+func (d *EntropyDetector) Detect(content string) [][2]int {
+	var matches [][2]int
+	for _, match := range entropyTokenPattern.FindAllStringIndex(content, -1) {
+		token := content[match[0]:match[1]]
+		if len(token) < d.MinLength || !strings.ContainsFunc(token, unicode.IsDigit) || !strings.ContainsFunc(token, unicode.IsLetter) {
+			continue
+		}
+		if shannonEntropy(token) >= d.Threshold {
+			matches = append(matches, [2]int{match[0], match[1]})
+		}
+	}
+	return matches
+}
+
+// shannonEntropy returns the Shannon entropy of s, in bits per character
+func shannonEntropy(s string) float64 {
+	frequencies := make(map[rune]float64)
+	for _, character := range s {
+		frequencies[character]++
+	}
+	var entropy float64
+	length := float64(len([]rune(s)))
+	for _, frequency := range frequencies {
+		probability := frequency / length
+		entropy -= probability * math.Log2(probability)
+	}
+	return entropy
+}
+
+// Built-in detectors
+var (
+	DetectorAWSAccessKeyID     = NewRegexpDetector("aws_access_key_id", `\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA)[0-9A-Z]{16}\b`)
+	DetectorAWSSecretAccessKey = NewRegexpDetector("aws_secret_access_key", `(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}`)
+	DetectorGCPAPIKey          = NewRegexpDetector("gcp_api_key", `\bAIza[0-9A-Za-z_\-]{35}\b`)
+	DetectorAzureStorageKey    = NewRegexpDetector("azure_storage_key", `AccountKey=[A-Za-z0-9+/=]{86,88}`)
+	DetectorPrivateKey         = NewRegexpDetector("private_key", `-----BEGIN (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----[\s\S]*?(?:-----END (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----|$)`)
+	DetectorJWT                = NewRegexpDetector("jwt", `\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+`)
+	DetectorBearerToken        = NewRegexpDetector("bearer_token", `(?i)\bbearer\s+[A-Za-z0-9\-._~+/]{16,}=*`)
+	DetectorPasswordInURL      = NewRegexpDetector("password_in_url", `\b[A-Za-z][A-Za-z0-9+.\-]*://[^\s:/@]+:[^\s@/]+@[^\s/]+`)
+	DetectorHighEntropyString  = &EntropyDetector{MinLength: 32, Threshold: 4.2}
+)
+
+// BuiltinDetectors returns every built-in detector
+func BuiltinDetectors() []Detector {
+	return []Detector{
+		DetectorAWSAccessKeyID,
+		DetectorAWSSecretAccessKey,
+		DetectorGCPAPIKey,
+		DetectorAzureStorageKey,
+		DetectorPrivateKey,
+		DetectorJWT,
+		DetectorBearerToken,
+		DetectorPasswordInURL,
+		DetectorHighEntropyString,
+	}
+}
+
+// ContentScanner is a Scanner that runs a set of detectors, each with its own action
+type ContentScanner struct {
+	rules []scannerRule
+}
+
+type scannerRule struct {
+	detector Detector
+	action   ScanAction
+}
+
+// NewContentScanner creates a new ContentScanner without any detector
+func NewContentScanner() *ContentScanner {
+	return &ContentScanner{}
+}
+
+// NewDefaultContentScanner creates a new ContentScanner with every built-in detector configured with the given action
+func NewDefaultContentScanner(action ScanAction) *ContentScanner {
+	scanner := NewContentScanner()
+	for _, detector := range BuiltinDetectors() {
+		scanner.WithDetector(detector, action)
+	}
+	return scanner
+}
+
+// WithDetector adds a detector to the scanner, or changes its action if it was already added
+//
+// Returns the scanner to allow chaining
+func (s *ContentScanner) WithDetector(detector Detector, action ScanAction) *ContentScanner {
+	for i, rule := range s.rules {
+		if rule.detector.Name() == detector.Name() {
+			s.rules[i].action = action
+			return s
+		}
+	}
+	s.rules = append(s.rules, scannerRule{detector: detector, action: action})
+	return s
+}
+
+// Scan returns the findings of every detector, sorted by position
+func (s *ContentScanner) Scan(content string) []Finding {
+	var findings []Finding
+	for _, rule := range s.rules {
+		for _, match := range rule.detector.Detect(content) {
+			findings = append(findings, Finding{Detector: rule.detector.Name(), Action: rule.action, Start: match[0], End: match[1]})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Start < findings[j].Start
+	})
+	return findings
+}
+
+// WithContentScanner configures the client to scan the content of every paste before it is created.
+// Passing nil disables scanning.
+//
+// Note that the data of binary pastes is scanned before being encoded, but cannot be redacted, and that the content of
+// encrypted pastes is scanned before encryption.
+//
+// Returns the client to allow chaining
+func (c *Client) WithContentScanner(scanner Scanner) *Client {
+	c.scanner = scanner
+	return c
+}
+
+// scanContent runs the scanner of the client on the content and returns the content with the findings configured
+// with ScanActionRedact redacted, or a *ContentRejectedError if any finding is configured with ScanActionDeny
+func (c *Client) scanContent(content string) (string, error) {
+	if c.scanner == nil {
+		return content, nil
+	}
+	denied, redacted := c.classifyFindings(c.scanner.Scan(content))
+	if len(denied) > 0 {
+		return "", &ContentRejectedError{Findings: denied}
+	}
+	return redactFindings(content, redacted), nil
+}
+
+// scanBinaryContent runs the scanner of the client on binary data, which cannot be redacted without corrupting it, so
+// any finding configured with ScanActionRedact or ScanActionDeny results in a *ContentRejectedError
+func (c *Client) scanBinaryContent(data []byte) error {
+	if c.scanner == nil {
+		return nil
+	}
+	denied, redacted := c.classifyFindings(c.scanner.Scan(string(data)))
+	if rejected := append(denied, redacted...); len(rejected) > 0 {
+		sort.SliceStable(rejected, func(i, j int) bool {
+			return rejected[i].Start < rejected[j].Start
+		})
+		return &ContentRejectedError{Findings: rejected}
+	}
+	return nil
+}
+
+// classifyFindings returns the findings configured with ScanActionDeny and ScanActionRedact, and logs the findings
+// configured with ScanActionWarn
+func (c *Client) classifyFindings(findings []Finding) (denied, redacted []Finding) {
+	for _, finding := range findings {
+		switch finding.Action {
+		case ScanActionDeny:
+			denied = append(denied, finding)
+		case ScanActionRedact:
+			redacted = append(redacted, finding)
+		default:
+			if c.logger != nil {
+				c.logger.logger.LogAttrs(context.Background(), slog.LevelWarn, "paste content may contain sensitive data",
+					slog.String("detector", finding.Detector),
+					slog.Int("start", finding.Start),
+					slog.Int("end", finding.End),
+				)
+			}
+		}
+	}
+	return denied, redacted
+}
+
+// redactFindings replaces the content matched by each finding, merging overlapping findings
+func redactFindings(content string, findings []Finding) string {
+	if len(findings) == 0 {
+		return content
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Start < findings[j].Start
+	})
+	var output strings.Builder
+	position := 0
+	for _, finding := range findings {
+		if finding.Start < position {
+			// The finding overlaps with the previous one, which was already redacted
+			position = max(position, finding.End)
+			continue
+		}
+		output.WriteString(content[position:finding.Start])
+		output.WriteString("[REDACTED:" + finding.Detector + "]")
+		position = finding.End
+	}
+	output.WriteString(content[position:])
+	return output.String()
+}
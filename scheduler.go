@@ -0,0 +1,369 @@
+package pastebin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	schedulerJobsVersion = 1
+
+	// DefaultMissedDeadlineTolerance is how late a scheduled action can be performed before it is reported as missed
+	DefaultMissedDeadlineTolerance = time.Minute
+)
+
+var (
+	ErrJobNotFound = errors.New("scheduled job not found")
+)
+
+// SchedulerAction is an action performed by a Scheduler
+type SchedulerAction string
+
+const (
+	SchedulerActionPublish SchedulerAction = "publish"
+	SchedulerActionDelete  SchedulerAction = "delete"
+)
+
+// ScheduledJob is a paste whose publication and deletion are handled by a Scheduler
+type ScheduledJob struct {
+	ID string `json:"id"`
+
+	Title      string     `json:"title"`
+	Content    string     `json:"content"`
+	Syntax     string     `json:"syntax"`
+	Visibility Visibility `json:"visibility"`
+
+	// PublishAt is when the paste is created
+	PublishAt time.Time `json:"publish_at"`
+
+	// Lifetime is how long the paste exists before being deleted, or 0 if it is never deleted
+	Lifetime time.Duration `json:"lifetime"`
+
+	// PasteKey is the key of the paste, once it has been published
+	PasteKey string `json:"paste_key,omitempty"`
+
+	// PublishedAt is when the paste was actually created, and DeleteAt is when it will be deleted
+	PublishedAt time.Time `json:"published_at,omitempty"`
+	DeleteAt    time.Time `json:"delete_at,omitempty"`
+
+	// Expiration is the expiration the paste was created with, which is the shortest expiration that cannot expire
+	// before Lifetime has elapsed, so that the paste is deleted by Pastebin if the scheduler isn't running anymore
+	Expiration Expiration `json:"expiration,omitempty"`
+
+	// LastError is the error of the last attempt to perform the next action of the job, if it failed
+	LastError string `json:"last_error,omitempty"`
+}
+
+// IsPublished returns whether the paste of the job has been created
+func (j *ScheduledJob) IsPublished() bool {
+	return len(j.PasteKey) > 0
+}
+
+// MissedDeadline is an action that was performed late, or skipped because it was too late to perform it
+type MissedDeadline struct {
+	JobID    string
+	Action   SchedulerAction
+	Deadline time.Time
+
+	// PerformedAt is when the action was performed, which is zero if the action was skipped
+	PerformedAt time.Time
+}
+
+// SchedulerReport is the result of Scheduler.RunPending
+type SchedulerReport struct {
+	// Published and Deleted are the jobs whose paste was published or deleted
+	Published []*ScheduledJob
+	Deleted   []*ScheduledJob
+
+	// Failed are the jobs that were removed because their paste can never be published, e.g. because it violates the
+	// policy of the client. Their LastError is the reason.
+	Failed []*ScheduledJob
+
+	// Missed are the actions that were performed more than SchedulerOptions.Tolerance after their deadline
+	Missed []MissedDeadline
+}
+
+// SchedulerOptions are the options of NewScheduler
+type SchedulerOptions struct {
+	// Tolerance is how late an action can be performed before it is reported as missed.
+	// Defaults to DefaultMissedDeadlineTolerance.
+	Tolerance time.Duration
+}
+
+// Scheduler publishes pastes at a given time and deletes them after an arbitrary duration, which allows pastes to
+// expire after durations that are not supported by Expiration.
+//
+// The jobs are persisted to a JSON file after every change, so that a scheduler created with the same file after a
+// restart still performs the pending publications and deletions, and reports the deadlines missed in the meantime.
+// Only one scheduler should use a given file at a time.
+type Scheduler struct {
+	client    *Client
+	path      string
+	tolerance time.Duration
+	jobs      []*ScheduledJob
+	mutex     sync.Mutex
+
+	// now returns the current time, and is only replaced in tests
+	now func() time.Time
+}
+
+type schedulerJobsFile struct {
+	Version int             `json:"version"`
+	Jobs    []*ScheduledJob `json:"jobs"`
+}
+
+// NewScheduler creates a Scheduler that uses the client to publish and delete pastes, and persists its jobs to the file
+// at path, loading the jobs it already contains
+func (c *Client) NewScheduler(path string, options SchedulerOptions) (*Scheduler, error) {
+	if options.Tolerance <= 0 {
+		options.Tolerance = DefaultMissedDeadlineTolerance
+	}
+	scheduler := &Scheduler{client: c, path: path, tolerance: options.Tolerance, now: time.Now}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return scheduler, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file schedulerJobsFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid scheduler jobs file %s: %w", path, err)
+	}
+	if file.Version != schedulerJobsVersion {
+		return nil, fmt.Errorf("unsupported scheduler jobs file version %d", file.Version)
+	}
+	scheduler.jobs = file.Jobs
+	return scheduler, nil
+}
+
+// Schedule adds a job that creates a paste from the request at publishAt, and deletes it once lifetime has elapsed.
+// A lifetime of 0 means that the paste is never deleted. The expiration of the request is ignored, since the paste is
+// created with the shortest expiration that cannot expire before lifetime has elapsed.
+//
+// The request is checked against the policy of the client and validated immediately, and its content is passed through
+// the scanner configured with WithContentScanner, so that content that would be rejected is never stored and content
+// that would be redacted is stored redacted. The paste is only created by RunPending or Run once publishAt is reached.
+func (s *Scheduler) Schedule(request *CreatePasteRequest, publishAt time.Time, lifetime time.Duration) (*ScheduledJob, error) {
+	if lifetime < 0 {
+		return nil, errors.New("lifetime must not be negative")
+	}
+	validatedRequest := *request
+	validatedRequest.Expiration = scheduledExpiration(lifetime)
+	if s.client.policy != nil {
+		if _, err := s.client.policy.Apply(&validatedRequest); err != nil {
+			return nil, err
+		}
+	}
+	if err := validatedRequest.Validate(s.client.Capabilities()); err != nil {
+		return nil, err
+	}
+	content, err := s.client.scanContent(request.Code)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	job := &ScheduledJob{
+		ID:         hex.EncodeToString(id),
+		Title:      request.Title,
+		Content:    content,
+		Syntax:     request.Syntax,
+		Visibility: request.Visibility,
+		PublishAt:  publishAt.UTC(),
+		Lifetime:   lifetime,
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.jobs = append(s.jobs, job)
+	if err := s.save(); err != nil {
+		s.jobs = s.jobs[:len(s.jobs)-1]
+		return nil, err
+	}
+	copied := *job
+	return &copied, nil
+}
+
+// Cancel removes a job. If the paste of the job was already published, it is not deleted.
+func (s *Scheduler) Cancel(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, job := range s.jobs {
+		if job.ID == id {
+			s.jobs = append(s.jobs[:i:i], s.jobs[i+1:]...)
+			return s.save()
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrJobNotFound, id)
+}
+
+// Jobs returns a copy of every pending job, ordered by the time of their next action
+func (s *Scheduler) Jobs() []*ScheduledJob {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	jobs := make([]*ScheduledJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		copied := *job
+		jobs = append(jobs, &copied)
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].nextActionAt().Before(jobs[j].nextActionAt())
+	})
+	return jobs
+}
+
+// nextActionAt returns when the next action of the job is due
+func (j *ScheduledJob) nextActionAt() time.Time {
+	if j.IsPublished() {
+		return j.DeleteAt
+	}
+	return j.PublishAt
+}
+
+// RunPending performs every publication and deletion that is due, and returns what was done, including the deadlines
+// that were missed, e.g. because the process wasn't running.
+//
+// A publication is skipped altogether if the paste should already have been deleted by the time it is performed.
+// Actions that fail are attempted again on the next call, and their errors are returned as a *BulkError. However, jobs
+// whose paste can never be published, e.g. because it violates the policy of the client, are removed and reported in
+// SchedulerReport.Failed instead of being attempted again.
+func (s *Scheduler) RunPending() (*SchedulerReport, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	report := &SchedulerReport{}
+	var errs []error
+	var remainingJobs []*ScheduledJob
+	for _, job := range s.jobs {
+		done, err := s.runJob(job, report)
+		if err != nil {
+			job.LastError = err.Error()
+			errs = append(errs, fmt.Errorf("%s: %w", job.ID, err))
+		}
+		if !done {
+			remainingJobs = append(remainingJobs, job)
+		}
+	}
+	total := len(s.jobs)
+	s.jobs = remainingJobs
+	if err := s.save(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to save jobs: %w", err))
+	}
+	if len(errs) > 0 {
+		return report, &BulkError{Total: total, Errs: errs}
+	}
+	return report, nil
+}
+
+// runJob performs the actions of the job that are due, and returns whether the job is complete
+func (s *Scheduler) runJob(job *ScheduledJob, report *SchedulerReport) (bool, error) {
+	now := s.now()
+	if !job.IsPublished() {
+		if now.Before(job.PublishAt) {
+			return false, nil
+		}
+		if job.Lifetime > 0 && !now.Before(job.PublishAt.Add(job.Lifetime)) {
+			report.Missed = append(report.Missed, MissedDeadline{JobID: job.ID, Action: SchedulerActionPublish, Deadline: job.PublishAt})
+			return true, nil
+		}
+		expiration := scheduledExpiration(job.Lifetime)
+		result, err := s.client.CreatePasteWithResult(NewCreatePasteRequest(job.Title, job.Content, expiration, job.Visibility, job.Syntax))
+		if err != nil {
+			// Publishing the paste again would fail the same way, e.g. if the policy of the client changed since the
+			// job was scheduled
+			if errors.Is(err, ErrInvalidCreatePasteRequest) || errors.Is(err, ErrPolicyViolation) || errors.Is(err, ErrContentRejected) {
+				job.LastError = err.Error()
+				copied := *job
+				report.Failed = append(report.Failed, &copied)
+				return true, err
+			}
+			return false, err
+		}
+		if now.Sub(job.PublishAt) > s.tolerance {
+			report.Missed = append(report.Missed, MissedDeadline{JobID: job.ID, Action: SchedulerActionPublish, Deadline: job.PublishAt, PerformedAt: now})
+		}
+		job.PasteKey, job.PublishedAt, job.Expiration, job.LastError = result.Key, now.UTC(), expiration, ""
+		// The lifetime is counted from the actual publication, so that late publications aren't cut short
+		if job.Lifetime > 0 {
+			job.DeleteAt = job.PublishedAt.Add(job.Lifetime)
+		}
+		copied := *job
+		report.Published = append(report.Published, &copied)
+		// The job is persisted right away, so that the paste isn't published again if the process stops
+		if err = s.save(); err != nil {
+			return false, err
+		}
+	}
+	if job.Lifetime == 0 {
+		return true, nil
+	}
+	if now.Before(job.DeleteAt) {
+		return false, nil
+	}
+	if err := s.client.DeletePaste(job.PasteKey); err != nil {
+		// If the paste already expired, there is nothing left to delete
+		if expiration := job.Expiration.Duration(); expiration > 0 && now.After(job.PublishedAt.Add(expiration)) {
+			report.Missed = append(report.Missed, MissedDeadline{JobID: job.ID, Action: SchedulerActionDelete, Deadline: job.DeleteAt})
+			return true, nil
+		}
+		return false, err
+	}
+	if now.Sub(job.DeleteAt) > s.tolerance {
+		report.Missed = append(report.Missed, MissedDeadline{JobID: job.ID, Action: SchedulerActionDelete, Deadline: job.DeleteAt, PerformedAt: now})
+	}
+	copied := *job
+	report.Deleted = append(report.Deleted, &copied)
+	return true, nil
+}
+
+// scheduledExpiration returns the expiration a paste with the given lifetime is created with
+func scheduledExpiration(lifetime time.Duration) Expiration {
+	if lifetime > 0 {
+		return ExpirationAtLeast(lifetime)
+	}
+	return ExpirationNever
+}
+
+// Run calls RunPending every interval until the context is canceled, passing the result of each call to onReport if
+// it isn't nil. Returns the error of the context, or an error if the interval isn't positive.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration, onReport func(report *SchedulerReport, err error)) error {
+	if interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		report, err := s.RunPending()
+		if onReport != nil {
+			onReport(report, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// save persists the jobs. Must be called with the mutex held.
+func (s *Scheduler) save() error {
+	jobs := s.jobs
+	if jobs == nil {
+		jobs = []*ScheduledJob{}
+	}
+	data, err := json.MarshalIndent(schedulerJobsFile{Version: schedulerJobsVersion, Jobs: jobs}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(s.path, data)
+}
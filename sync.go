@@ -0,0 +1,424 @@
+package pastebin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultSyncStateFileName is the name of the state file created in the synchronized directory when no state file
+// is specified in SyncOptions
+const DefaultSyncStateFileName = ".pastebin-sync.json"
+
+const syncStateVersion = 1
+
+// syntaxesByExtension maps file extensions to the syntax used for the pastes created by Sync
+var syntaxesByExtension = map[string]string{
+	".c":    "c",
+	".cpp":  "cpp",
+	".cs":   "csharp",
+	".css":  "css",
+	".go":   "go",
+	".html": "html5",
+	".java": "java",
+	".js":   "javascript",
+	".json": "json",
+	".kt":   "kotlin",
+	".md":   "markdown",
+	".php":  "php",
+	".py":   "python",
+	".rb":   "ruby",
+	".rs":   "rust",
+	".sh":   "bash",
+	".sql":  "sql",
+	".ts":   "typescript",
+	".xml":  "xml",
+	".yaml": "yaml",
+	".yml":  "yaml",
+}
+
+// SyncAction is a change planned by Sync
+type SyncAction string
+
+const (
+	// SyncActionCreate creates a paste for a local file that has no paste
+	SyncActionCreate SyncAction = "create"
+
+	// SyncActionReplace creates a new paste for a local file whose content changed, and deletes the previous paste,
+	// since Pastebin's API does not allow pastes to be edited
+	SyncActionReplace SyncAction = "replace"
+
+	// SyncActionDelete deletes the paste of a local file that was deleted
+	SyncActionDelete SyncAction = "delete"
+
+	// SyncActionPull writes a paste that has no local file to the directory
+	SyncActionPull SyncAction = "pull"
+
+	// SyncActionDeleteLocal deletes an unchanged local file whose paste was deleted
+	SyncActionDeleteLocal SyncAction = "delete_local"
+
+	// SyncActionTrack records a local file and a paste that have the same content in the state file
+	SyncActionTrack SyncAction = "track"
+)
+
+// SyncChange is a single change of a SyncPlan
+type SyncChange struct {
+	Action SyncAction
+
+	// Path is the slash-separated path of the file, relative to the synchronized directory
+	Path string
+
+	// PasteKey is the key of the existing paste affected by the change, if any
+	PasteKey string
+}
+
+func (sc SyncChange) String() string {
+	if len(sc.PasteKey) == 0 {
+		return fmt.Sprintf("%-12s %s", sc.Action, sc.Path)
+	}
+	return fmt.Sprintf("%-12s %s (%s)", sc.Action, sc.Path, sc.PasteKey)
+}
+
+// SyncPlan is the list of changes needed for a directory and Pastebin to converge
+type SyncPlan struct {
+	Changes []SyncChange
+
+	directory   string
+	stateFile   string
+	localFiles  map[string]*localSyncFile
+	state       *SyncState
+	forgotten   []string
+	titlePrefix string
+
+	// pastes are the pastes listed by Sync, keyed by paste key
+	pastes map[string]*Paste
+}
+
+// String returns the changes of the plan, one per line
+func (sp *SyncPlan) String() string {
+	if len(sp.Changes) == 0 {
+		return "nothing to do\n"
+	}
+	var output strings.Builder
+	for _, change := range sp.Changes {
+		output.WriteString(change.String())
+		output.WriteByte('\n')
+	}
+	return output.String()
+}
+
+// SyncState is the content of the state file of Sync, which maps the path of each synchronized file to its paste
+type SyncState struct {
+	Version int                       `json:"version"`
+	Files   map[string]*SyncStateFile `json:"files"`
+}
+
+// SyncStateFile is the state of a single synchronized file
+type SyncStateFile struct {
+	PasteKey string `json:"paste_key"`
+
+	// SHA256 is the hex-encoded SHA-256 checksum of the content of the file when it was last synchronized
+	SHA256 string `json:"sha256"`
+}
+
+// SyncOptions are the options of Sync
+type SyncOptions struct {
+	// StateFile is the path of the state file. Defaults to DefaultSyncStateFileName in the synchronized directory.
+	StateFile string
+
+	// TitlePrefix is prepended to the path of each file to form the title of its paste.
+	// Pastes whose title starts with the prefix but that have no local file are pulled into the directory, and files
+	// that aren't in the state file yet are matched with the paste that has their title. Since this would take over
+	// unrelated pastes of the account, pastes are only pulled and matched by title when a prefix is configured.
+	TitlePrefix string
+
+	// Visibility and Expiration are used for the pastes created
+	Visibility Visibility
+	Expiration Expiration
+
+	// DryRun only plans the changes, without applying them
+	DryRun bool
+}
+
+type localSyncFile struct {
+	content string
+	sha256  string
+}
+
+// Sync makes the pastes of the authenticated user mirror the files of a directory, in both directions.
+//
+// Each file is matched to a paste by the state file, or by title if the file isn't in the state file yet and a
+// TitlePrefix is configured, in which case the content of the paste is compared with the content of the file. Files
+// that were created or modified locally are created or replaced on Pastebin, and the pastes of files that were
+// deleted locally are deleted. Pastes that were deleted on Pastebin have their local file deleted if it hasn't changed
+// since the last sync. Since Pastebin lists at most MaxUserPastes pastes, a paste missing from the list is only
+// considered deleted once CheckPaste confirms that it no longer exists.
+//
+// Hidden files and directories (e.g. .git) and empty files are ignored.
+//
+// The changes are applied one at a time and every change is attempted, in which case the plan is returned along with
+// a *BulkError. The state file is updated with the changes that succeeded.
+func (c *Client) Sync(directory string, options SyncOptions) (*SyncPlan, error) {
+	plan, err := c.planSync(directory, options)
+	if err != nil || options.DryRun {
+		return plan, err
+	}
+	return plan, c.applySync(plan, options)
+}
+
+func (c *Client) planSync(directory string, options SyncOptions) (*SyncPlan, error) {
+	stateFile := options.StateFile
+	if len(stateFile) == 0 {
+		stateFile = filepath.Join(directory, DefaultSyncStateFileName)
+	}
+	state, err := readSyncState(stateFile)
+	if err != nil {
+		return nil, err
+	}
+	localFiles, err := readLocalSyncFiles(directory, stateFile)
+	if err != nil {
+		return nil, err
+	}
+	pastes, _, err := c.getAllUserPastes()
+	if err != nil {
+		return nil, err
+	}
+	pastesByKey := make(map[string]*Paste)
+	pastesByPath := make(map[string]*Paste)
+	listedPastes := make(map[string]*Paste, len(pastes))
+	for _, paste := range pastes {
+		pastesByKey[paste.Key] = paste
+		listedPastes[paste.Key] = paste
+		if len(options.TitlePrefix) == 0 || !strings.HasPrefix(paste.Title, options.TitlePrefix) {
+			continue
+		}
+		// If multiple pastes have the same title, the most recent one is used
+		filePath := strings.TrimPrefix(paste.Title, options.TitlePrefix)
+		if existing, exists := pastesByPath[filePath]; !exists || paste.Date.After(existing.Date) {
+			pastesByPath[filePath] = paste
+		}
+	}
+	for filePath, syncedFile := range state.Files {
+		if _, listed := pastesByKey[syncedFile.PasteKey]; listed {
+			continue
+		}
+		// The list may be truncated, so the paste must not be considered deleted unless Pastebin confirms it
+		status, err := c.CheckPaste(syncedFile.PasteKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check the paste of %s: %w", filePath, err)
+		}
+		if status.IsAccessible() {
+			pastesByKey[syncedFile.PasteKey] = &Paste{Key: syncedFile.PasteKey}
+		}
+	}
+	plan := &SyncPlan{directory: directory, stateFile: stateFile, localFiles: localFiles, state: state, titlePrefix: options.TitlePrefix, pastes: listedPastes}
+	paths := make(map[string]bool)
+	for filePath := range localFiles {
+		paths[filePath] = true
+	}
+	for filePath := range state.Files {
+		paths[filePath] = true
+	}
+	for filePath := range pastesByPath {
+		if isSyncablePath(filePath) {
+			paths[filePath] = true
+		}
+	}
+	sortedPaths := make([]string, 0, len(paths))
+	for filePath := range paths {
+		sortedPaths = append(sortedPaths, filePath)
+	}
+	sort.Strings(sortedPaths)
+	for _, filePath := range sortedPaths {
+		localFile, hasLocalFile := localFiles[filePath]
+		syncedFile, hasState := state.Files[filePath]
+		var trackedPaste *Paste
+		if hasState {
+			trackedPaste = pastesByKey[syncedFile.PasteKey]
+		}
+		switch {
+		case hasLocalFile && trackedPaste != nil:
+			if localFile.sha256 != syncedFile.SHA256 {
+				plan.Changes = append(plan.Changes, SyncChange{Action: SyncActionReplace, Path: filePath, PasteKey: trackedPaste.Key})
+			}
+		case hasLocalFile && hasState:
+			// The paste was deleted on Pastebin
+			if localFile.sha256 == syncedFile.SHA256 {
+				plan.Changes = append(plan.Changes, SyncChange{Action: SyncActionDeleteLocal, Path: filePath, PasteKey: syncedFile.PasteKey})
+			} else {
+				plan.Changes = append(plan.Changes, SyncChange{Action: SyncActionCreate, Path: filePath})
+			}
+		case hasLocalFile:
+			paste, exists := pastesByPath[filePath]
+			if !exists {
+				plan.Changes = append(plan.Changes, SyncChange{Action: SyncActionCreate, Path: filePath})
+				break
+			}
+			content, err := c.GetUserPasteContent(paste.Key)
+			if err != nil {
+				return nil, err
+			}
+			if checksum := sha256.Sum256([]byte(content)); hex.EncodeToString(checksum[:]) == localFile.sha256 {
+				plan.Changes = append(plan.Changes, SyncChange{Action: SyncActionTrack, Path: filePath, PasteKey: paste.Key})
+			} else {
+				plan.Changes = append(plan.Changes, SyncChange{Action: SyncActionReplace, Path: filePath, PasteKey: paste.Key})
+			}
+		case trackedPaste != nil:
+			plan.Changes = append(plan.Changes, SyncChange{Action: SyncActionDelete, Path: filePath, PasteKey: trackedPaste.Key})
+		case hasState:
+			// Both the file and the paste were deleted, so the file only has to be removed from the state
+			plan.forgotten = append(plan.forgotten, filePath)
+		default:
+			plan.Changes = append(plan.Changes, SyncChange{Action: SyncActionPull, Path: filePath, PasteKey: pastesByPath[filePath].Key})
+		}
+	}
+	return plan, nil
+}
+
+func (c *Client) applySync(plan *SyncPlan, options SyncOptions) error {
+	for _, filePath := range plan.forgotten {
+		delete(plan.state.Files, filePath)
+	}
+	var errs []error
+	for _, change := range plan.Changes {
+		if err := c.applySyncChange(plan, change, options); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", change.Action, change.Path, err))
+		}
+	}
+	data, err := json.MarshalIndent(plan.state, "", "  ")
+	if err == nil {
+		err = writeFileAtomically(plan.stateFile, data)
+	}
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to write state file: %w", err))
+	}
+	if len(errs) > 0 {
+		return &BulkError{Total: len(plan.Changes), Errs: errs}
+	}
+	return nil
+}
+
+func (c *Client) applySyncChange(plan *SyncPlan, change SyncChange, options SyncOptions) error {
+	localFilePath := filepath.Join(plan.directory, filepath.FromSlash(change.Path))
+	switch change.Action {
+	case SyncActionCreate, SyncActionReplace:
+		localFile := plan.localFiles[change.Path]
+		syntax, exists := syntaxesByExtension[strings.ToLower(path.Ext(change.Path))]
+		if !exists {
+			syntax = "text"
+		}
+		result, err := c.CreatePasteWithResult(NewCreatePasteRequest(plan.titlePrefix+change.Path, localFile.content, options.Expiration, options.Visibility, syntax))
+		if err != nil {
+			return err
+		}
+		plan.state.Files[change.Path] = &SyncStateFile{PasteKey: result.Key, SHA256: localFile.sha256}
+		if change.Action == SyncActionReplace {
+			return c.deletePaste(change.PasteKey, plan.pastes[change.PasteKey])
+		}
+	case SyncActionDelete:
+		if err := c.deletePaste(change.PasteKey, plan.pastes[change.PasteKey]); err != nil {
+			return err
+		}
+		delete(plan.state.Files, change.Path)
+	case SyncActionPull:
+		content, err := c.GetUserPasteContent(change.PasteKey)
+		if err != nil {
+			return err
+		}
+		if err = os.MkdirAll(filepath.Dir(localFilePath), 0o755); err != nil {
+			return err
+		}
+		if err = writeFileAtomically(localFilePath, []byte(content)); err != nil {
+			return err
+		}
+		checksum := sha256.Sum256([]byte(content))
+		plan.state.Files[change.Path] = &SyncStateFile{PasteKey: change.PasteKey, SHA256: hex.EncodeToString(checksum[:])}
+	case SyncActionDeleteLocal:
+		if err := os.Remove(localFilePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		delete(plan.state.Files, change.Path)
+	case SyncActionTrack:
+		plan.state.Files[change.Path] = &SyncStateFile{PasteKey: change.PasteKey, SHA256: plan.localFiles[change.Path].sha256}
+	}
+	return nil
+}
+
+// readSyncState reads the state file, returning an empty state if it doesn't exist
+func readSyncState(stateFile string) (*SyncState, error) {
+	state := &SyncState{Version: syncStateVersion, Files: make(map[string]*SyncStateFile)}
+	data, err := os.ReadFile(stateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %w", stateFile, err)
+	}
+	if state.Version != syncStateVersion {
+		return nil, fmt.Errorf("unsupported state file version %d", state.Version)
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]*SyncStateFile)
+	}
+	return state, nil
+}
+
+// readLocalSyncFiles reads every file of the directory that should be synchronized, keyed by slash-separated path
+func readLocalSyncFiles(directory, stateFile string) (map[string]*localSyncFile, error) {
+	absoluteStateFile, _ := filepath.Abs(stateFile)
+	localFiles := make(map[string]*localSyncFile)
+	err := filepath.WalkDir(directory, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath != directory && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		if absoluteFilePath, _ := filepath.Abs(filePath); absoluteFilePath == absoluteStateFile {
+			return nil
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil || len(data) == 0 {
+			return err
+		}
+		relativePath, err := filepath.Rel(directory, filePath)
+		if err != nil {
+			return err
+		}
+		checksum := sha256.Sum256(data)
+		localFiles[filepath.ToSlash(relativePath)] = &localSyncFile{content: string(data), sha256: hex.EncodeToString(checksum[:])}
+		return nil
+	})
+	return localFiles, err
+}
+
+// isSyncablePath returns whether a paste title (without the prefix) can be used as the path of a pulled file, which
+// must stay within the synchronized directory and must not be hidden
+func isSyncablePath(filePath string) bool {
+	if !filepath.IsLocal(filepath.FromSlash(filePath)) || strings.Contains(filePath, `\`) {
+		return false
+	}
+	for _, segment := range strings.Split(filePath, "/") {
+		if len(segment) == 0 || strings.HasPrefix(segment, ".") {
+			return false
+		}
+	}
+	return true
+}
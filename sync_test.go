@@ -0,0 +1,186 @@
+package pastebin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Sync(t *testing.T) {
+	account := newMockAccount()
+	account.pastes["paste001"] = &mockAccountPaste{title: "snippets/a.go", content: "package a", syntax: "go", date: 1600000000}
+	account.pastes["paste002"] = &mockAccountPaste{title: "snippets/c.md", content: "# c", syntax: "markdown", date: 1600000000}
+	account.pastes["paste003"] = &mockAccountPaste{title: "unrelated", content: "unrelated", syntax: "text", date: 1600000000}
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	directory := t.TempDir()
+	writeFile := func(name, content string) {
+		path := filepath.Join(directory, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("a.go", "package a")
+	writeFile("notes/b.txt", "b")
+	writeFile("empty.txt", "")
+	writeFile(".git/config", "ignored")
+	options := SyncOptions{TitlePrefix: "snippets/", Visibility: VisibilityPrivate, DryRun: true}
+	sync := func(expectedChanges []SyncChange) {
+		t.Helper()
+		plan, err := client.Sync(directory, options)
+		if err != nil {
+			t.Fatal("shouldn't have returned an error, got", err)
+		}
+		if !reflect.DeepEqual(plan.Changes, expectedChanges) {
+			t.Fatalf("expected changes %v, got %v", expectedChanges, plan.Changes)
+		}
+	}
+	initialChanges := []SyncChange{
+		{Action: SyncActionTrack, Path: "a.go", PasteKey: "paste001"},
+		{Action: SyncActionPull, Path: "c.md", PasteKey: "paste002"},
+		{Action: SyncActionCreate, Path: "notes/b.txt"},
+	}
+	sync(initialChanges)
+	if _, err := os.Stat(filepath.Join(directory, DefaultSyncStateFileName)); !os.IsNotExist(err) {
+		t.Error("a dry run shouldn't have written the state file")
+	}
+	if len(account.pastes) != 3 {
+		t.Error("a dry run shouldn't have created any paste")
+	}
+	options.DryRun = false
+	sync(initialChanges)
+	if content, _ := os.ReadFile(filepath.Join(directory, "c.md")); string(content) != "# c" {
+		t.Errorf("expected c.md to have been pulled, got %q", content)
+	}
+	created := account.pastes["new00001"]
+	if created == nil || created.title != "snippets/notes/b.txt" || created.content != "b" || created.visibility != int(VisibilityPrivate) {
+		t.Fatalf("expected notes/b.txt to have been created, got %+v", created)
+	}
+	sync(nil)
+	// Modify a file, delete a file locally and delete a paste remotely
+	writeFile("a.go", "package a // modified")
+	_ = os.Remove(filepath.Join(directory, "notes", "b.txt"))
+	delete(account.pastes, "paste002")
+	sync([]SyncChange{
+		{Action: SyncActionReplace, Path: "a.go", PasteKey: "paste001"},
+		{Action: SyncActionDeleteLocal, Path: "c.md", PasteKey: "paste002"},
+		{Action: SyncActionDelete, Path: "notes/b.txt", PasteKey: "new00001"},
+	})
+	if _, exists := account.pastes["paste001"]; exists {
+		t.Error("the previous paste of a.go should have been deleted")
+	}
+	if replaced := account.pastes["new00002"]; replaced == nil || replaced.content != "package a // modified" || replaced.syntax != "go" {
+		t.Errorf("expected a.go to have been replaced, got %+v", replaced)
+	}
+	if _, exists := account.pastes["new00001"]; exists {
+		t.Error("the paste of notes/b.txt should have been deleted")
+	}
+	if _, err := os.Stat(filepath.Join(directory, "c.md")); !os.IsNotExist(err) {
+		t.Error("c.md should have been deleted")
+	}
+	if _, exists := account.pastes["paste003"]; !exists {
+		t.Error("pastes outside of the title prefix shouldn't have been touched")
+	}
+	state, err := readSyncState(filepath.Join(directory, DefaultSyncStateFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Files) != 1 || state.Files["a.go"].PasteKey != "new00002" {
+		t.Errorf("unexpected state %+v", state.Files)
+	}
+	sync(nil)
+}
+
+func TestClient_SyncWithFailures(t *testing.T) {
+	account := newMockAccount()
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	client.WithPolicy(&Policy{AllowedSyntaxes: []string{"text"}})
+	directory := t.TempDir()
+	_ = os.WriteFile(filepath.Join(directory, "a.txt"), []byte("a"), 0o644)
+	_ = os.WriteFile(filepath.Join(directory, "b.go"), []byte("package b"), 0o644)
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	plan, err := client.Sync(directory, SyncOptions{StateFile: stateFile})
+	var bulkError *BulkError
+	if !errors.As(err, &bulkError) || len(bulkError.Errs) != 1 || bulkError.Total != 2 {
+		t.Fatalf("expected a *BulkError with 1 of 2 errors, got %v", err)
+	}
+	if !errors.Is(err, ErrPolicyViolation) {
+		t.Error("expected the error to wrap ErrPolicyViolation, got", err)
+	}
+	if len(plan.Changes) != 2 {
+		t.Errorf("expected 2 changes, got %v", plan.Changes)
+	}
+	state, _ := readSyncState(stateFile)
+	if len(state.Files) != 1 || state.Files["a.txt"] == nil {
+		t.Errorf("expected only a.txt to be in the state, got %+v", state.Files)
+	}
+}
+
+func TestClient_SyncWithTruncatedList(t *testing.T) {
+	account := newMockAccount()
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	directory := t.TempDir()
+	_ = os.WriteFile(filepath.Join(directory, "a.txt"), []byte("a"), 0o644)
+	_ = os.WriteFile(filepath.Join(directory, "b.txt"), []byte("b"), 0o644)
+	if _, err := client.Sync(directory, SyncOptions{Visibility: VisibilityPrivate}); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	// Both pastes are missing from the list, but only the paste of b.txt was actually deleted
+	account.unlisted["new00001"] = true
+	delete(account.pastes, "new00002")
+	_ = os.WriteFile(filepath.Join(directory, "a.txt"), []byte("a // modified"), 0o644)
+	plan, err := client.Sync(directory, SyncOptions{DryRun: true})
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	expectedChanges := []SyncChange{
+		{Action: SyncActionReplace, Path: "a.txt", PasteKey: "new00001"},
+		{Action: SyncActionDeleteLocal, Path: "b.txt", PasteKey: "new00002"},
+	}
+	if !reflect.DeepEqual(plan.Changes, expectedChanges) {
+		t.Errorf("expected changes %v, got %v", expectedChanges, plan.Changes)
+	}
+}
+
+func TestClient_SyncWithoutTitlePrefix(t *testing.T) {
+	account := newMockAccount()
+	account.pastes["paste001"] = &mockAccountPaste{title: "a.txt", content: "unrelated", syntax: "text", date: 1600000000}
+	account.pastes["paste002"] = &mockAccountPaste{title: "b.txt", content: "b", syntax: "text", date: 1600000000}
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	directory := t.TempDir()
+	_ = os.WriteFile(filepath.Join(directory, "a.txt"), []byte("a"), 0o644)
+	plan, err := client.Sync(directory, SyncOptions{DryRun: true})
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	expectedChanges := []SyncChange{{Action: SyncActionCreate, Path: "a.txt"}}
+	if !reflect.DeepEqual(plan.Changes, expectedChanges) {
+		t.Errorf("pastes shouldn't be matched or pulled by title without a prefix, got %v", plan.Changes)
+	}
+}
+
+func TestIsSyncablePath(t *testing.T) {
+	scenarios := map[string]bool{
+		"a.go":          true,
+		"notes/b.txt":   true,
+		"":              false,
+		"../a.go":       false,
+		"/etc/passwd":   false,
+		"notes/../../a": false,
+		".hidden":       false,
+		"notes/.git/a":  false,
+		"notes//a":      false,
+		`notes\a`:       false,
+	}
+	for filePath, expected := range scenarios {
+		if actual := isSyncablePath(filePath); actual != expected {
+			t.Errorf("expected isSyncablePath(%q) to return %v, got %v", filePath, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package pastebin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotInTrash = errors.New("paste not found in trash")
+)
+
+// Trash stores the content and metadata of deleted pastes in a local directory, so that they can be recreated with
+// Client.Restore. Each paste is stored in its own JSON file, named after the key of the paste.
+type Trash struct {
+	directory string
+}
+
+// TrashedPaste is a paste stored in a Trash
+type TrashedPaste struct {
+	Key        string     `json:"key"`
+	Title      string     `json:"title"`
+	Syntax     string     `json:"syntax"`
+	Visibility Visibility `json:"visibility"`
+	Date       time.Time  `json:"date"`
+
+	// ExpireDate is the expiration date of the paste, which is the Unix epoch if the paste never expires
+	ExpireDate time.Time `json:"expire_date"`
+	DeletedAt  time.Time `json:"deleted_at"`
+	Content    string    `json:"content"`
+
+	// MetadataUnknown is true if the metadata of the paste could not be retrieved when it was deleted, in which case
+	// it's restored as an untitled private paste that never expires
+	MetadataUnknown bool `json:"metadata_unknown,omitempty"`
+}
+
+// NewTrash creates a Trash stored in the given directory, which is created when the first paste is trashed if it
+// doesn't exist
+func NewTrash(directory string) *Trash {
+	return &Trash{directory: directory}
+}
+
+// Get returns a paste from the trash, or ErrNotInTrash if the trash doesn't contain the paste
+func (t *Trash) Get(pasteKey string) (*TrashedPaste, error) {
+	path, err := t.path(pasteKey)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotInTrash, pasteKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var trashedPaste TrashedPaste
+	if err = json.Unmarshal(data, &trashedPaste); err != nil {
+		return nil, fmt.Errorf("invalid trash file %s: %w", path, err)
+	}
+	return &trashedPaste, nil
+}
+
+// List returns every paste in the trash, from the most recently deleted to the least recently deleted
+func (t *Trash) List() ([]*TrashedPaste, error) {
+	entries, err := os.ReadDir(t.directory)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var trashedPastes []*TrashedPaste
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		trashedPaste, err := t.Get(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		trashedPastes = append(trashedPastes, trashedPaste)
+	}
+	sort.SliceStable(trashedPastes, func(i, j int) bool {
+		return trashedPastes[i].DeletedAt.After(trashedPastes[j].DeletedAt)
+	})
+	return trashedPastes, nil
+}
+
+// Remove permanently removes a paste from the trash, if the trash contains it
+func (t *Trash) Remove(pasteKey string) error {
+	path, err := t.path(pasteKey)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (t *Trash) put(trashedPaste *TrashedPaste) error {
+	path, err := t.path(trashedPaste.Key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(t.directory, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(trashedPaste, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(path, data)
+}
+
+// path returns the path of the file of a paste, validating the key so that it cannot escape the directory
+func (t *Trash) path(pasteKey string) (string, error) {
+	if err := ValidatePasteKey(pasteKey); err != nil {
+		return "", err
+	}
+	return filepath.Join(t.directory, pasteKey+".json"), nil
+}
+
+// WithTrash configures the client to move pastes to the trash rather than deleting them permanently.
+// Passing nil removes the trash.
+//
+// Before a paste is deleted by DeletePaste (and therefore by every function deleting pastes, such as DeletePastes and
+// UpdatePaste), the client retrieves its content with GetUserPasteContent, which also verifies that the paste belongs
+// to the authenticated user, and stores it in the trash along with the metadata of the paste, from which it can be
+// recreated with Restore. If Pastebin refuses to delete the paste, it's removed from the trash, but if the delete request
+// fails for any other reason, e.g. a timeout, the paste is kept in the trash, since it may have been deleted anyway.
+//
+// Returns the client to allow chaining
+func (c *Client) WithTrash(trash *Trash) *Client {
+	c.trash = trash
+	return c
+}
+
+// trashPaste stores a paste owned by the authenticated user in the trash.
+//
+// If the caller doesn't have the metadata of the paste, it's looked up in the MaxUserPastes most recent pastes of the
+// user, but since that list is limited to the most recent pastes, the paste is trashed without its metadata if it's not
+// there.
+func (c *Client) trashPaste(pasteKey string, paste *Paste) error {
+	content, err := c.getUserPasteContent(pasteKey)
+	if err != nil {
+		return err
+	}
+	if paste == nil {
+		paste, _ = c.getUserPaste(pasteKey)
+	}
+	trashedPaste := &TrashedPaste{Key: pasteKey, Visibility: VisibilityPrivate, DeletedAt: time.Now().UTC(), Content: content}
+	if paste == nil {
+		trashedPaste.MetadataUnknown = true
+	} else {
+		trashedPaste.Title = paste.Title
+		trashedPaste.Syntax = paste.Syntax
+		trashedPaste.Visibility = paste.Visibility
+		trashedPaste.Date = paste.Date
+		trashedPaste.ExpireDate = paste.ExpireDate
+	}
+	return c.trash.put(trashedPaste)
+}
+
+// Restore recreates a paste from the trash configured with WithTrash, and returns the key of the new paste.
+//
+// The paste is recreated with the same title, syntax and visibility, as well as the expiration closest to the time it
+// has left, after which it is removed from the trash. If the client has an AliasRegistry (see
+// WithAliases), the aliases of the deleted paste are updated to point to the new paste.
+// Pastes that would have expired by now cannot be restored, but remain in the trash.
+func (c *Client) Restore(pasteKey string) (string, error) {
+	if c.trash == nil {
+		return "", errors.New("no trash configured, see WithTrash")
+	}
+	trashedPaste, err := c.trash.Get(pasteKey)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	if trashedPaste.ExpireDate.Unix() > 0 && !trashedPaste.ExpireDate.After(now) {
+		return "", fmt.Errorf("paste %s expired on %s", pasteKey, trashedPaste.ExpireDate.Format(time.RFC3339))
+	}
+	result, err := c.CreatePasteWithResult(NewCreatePasteRequest(trashedPaste.Title, trashedPaste.Content, remainingExpiration(trashedPaste.ExpireDate, now), trashedPaste.Visibility, trashedPaste.Syntax))
+	if err != nil {
+		return "", err
+	}
+	if c.aliases != nil {
+		if err = c.aliases.replacePasteKey(pasteKey, result.Key); err != nil {
+			return result.Key, fmt.Errorf("restored paste %s as %s but failed to update its aliases: %w", pasteKey, result.Key, err)
+		}
+	}
+	if err = c.trash.Remove(pasteKey); err != nil {
+		return result.Key, fmt.Errorf("restored paste %s as %s but failed to remove it from the trash: %w", pasteKey, result.Key, err)
+	}
+	return result.Key, nil
+}
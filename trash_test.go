@@ -0,0 +1,176 @@
+package pastebin
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripperFunc is an http.RoundTripper that can return an error, unlike test.MockRoundTripper
+type roundTripperFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestClient_DeletePasteWithTrash(t *testing.T) {
+	account := newMockAccount()
+	account.pastes["paste001"] = &mockAccountPaste{title: "runbook", content: "content", syntax: "markdown", visibility: int(VisibilityPrivate), date: 1600000000}
+	httpClient = account.httpClient()
+	trash := NewTrash(filepath.Join(t.TempDir(), "trash"))
+	client, _ := NewClient("username", "password", "token")
+	client.WithTrash(trash)
+	if err := client.DeletePaste("paste404"); !errors.Is(err, ErrPasteNotOwned) {
+		t.Error("expected ErrPasteNotOwned, got", err)
+	}
+	if err := client.DeletePaste("paste001"); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if _, exists := account.pastes["paste001"]; exists {
+		t.Error("the paste should have been deleted")
+	}
+	trashedPaste, err := trash.Get("paste001")
+	if err != nil {
+		t.Fatal("the paste should have been moved to the trash, got", err)
+	}
+	if trashedPaste.Title != "runbook" || trashedPaste.Content != "content" || trashedPaste.Syntax != "markdown" || trashedPaste.Visibility != VisibilityPrivate || trashedPaste.DeletedAt.IsZero() {
+		t.Errorf("unexpected trashed paste %+v", trashedPaste)
+	}
+	if trashedPastes, _ := trash.List(); len(trashedPastes) != 1 {
+		t.Errorf("expected 1 paste in the trash, got %d", len(trashedPastes))
+	}
+}
+
+func TestClient_DeletePasteWithTrashAndUnlistedPaste(t *testing.T) {
+	account := newMockAccount()
+	account.pastes["paste001"] = &mockAccountPaste{title: "old runbook", content: "content", syntax: "markdown", visibility: int(VisibilityUnlisted), date: 1600000000}
+	account.unlisted["paste001"] = true
+	httpClient = account.httpClient()
+	trash := NewTrash(t.TempDir())
+	client, _ := NewClient("username", "password", "token")
+	client.WithTrash(trash)
+	if err := client.DeletePaste("paste001"); err != nil {
+		t.Fatal("a paste missing from the list should still be deleted, got", err)
+	}
+	trashedPaste, err := trash.Get("paste001")
+	if err != nil {
+		t.Fatal("the paste should have been moved to the trash, got", err)
+	}
+	if !trashedPaste.MetadataUnknown || trashedPaste.Content != "content" || trashedPaste.Visibility != VisibilityPrivate {
+		t.Errorf("unexpected trashed paste %+v", trashedPaste)
+	}
+	newPasteKey, err := client.Restore("paste001")
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if paste := account.pastes[newPasteKey]; paste == nil || paste.content != "content" || paste.visibility != int(VisibilityPrivate) || paste.expiration != string(ExpirationNever) {
+		t.Errorf("expected the paste to have been recreated as a private paste, got %+v", paste)
+	}
+}
+
+func TestClient_DeletePasteWithTrashAndTransportError(t *testing.T) {
+	account := newMockAccount()
+	account.pastes["paste001"] = &mockAccountPaste{title: "runbook", content: "content"}
+	account.pastes["paste002"] = &mockAccountPaste{title: "notes", content: "content"}
+	mockHttpClient := account.httpClient()
+	httpClient = &http.Client{Transport: roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		var body []byte
+		if request.Body != nil {
+			body, _ = io.ReadAll(request.Body)
+			request.Body = io.NopCloser(strings.NewReader(string(body)))
+		}
+		if strings.Contains(string(body), "api_option=delete") && strings.Contains(string(body), "api_paste_key=paste001") {
+			// The paste is deleted, but the response never makes it back
+			_, _ = mockHttpClient.Transport.RoundTrip(request)
+			return nil, errors.New("connection reset by peer")
+		}
+		if strings.Contains(string(body), "api_option=delete") && strings.Contains(string(body), "api_paste_key=paste002") {
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("Bad API request, invalid permission to remove paste"))}, nil
+		}
+		return mockHttpClient.Transport.RoundTrip(request)
+	})}
+	trash := NewTrash(t.TempDir())
+	client, _ := NewClient("username", "password", "token")
+	client.WithTrash(trash)
+	if err := client.DeletePaste("paste001"); err == nil {
+		t.Fatal("should've returned an error")
+	}
+	if _, err := trash.Get("paste001"); err != nil {
+		t.Error("the paste should've been kept in the trash, since it may have been deleted, got", err)
+	}
+	// A paste that Pastebin refuses to delete still exists, so it must not stay in the trash
+	if err := client.DeletePaste("paste002"); err == nil || !strings.HasPrefix(err.Error(), "Bad API request") {
+		t.Fatal("expected the API error to be returned, got", err)
+	}
+	if _, err := trash.Get("paste002"); err == nil {
+		t.Error("the paste should've been removed from the trash, since it still exists")
+	}
+}
+
+func TestClient_Restore(t *testing.T) {
+	account := newMockAccount()
+	account.pastes["paste001"] = &mockAccountPaste{title: "runbook", content: "content", syntax: "markdown", visibility: int(VisibilityUnlisted), date: 1600000000}
+	httpClient = account.httpClient()
+	trash := NewTrash(t.TempDir())
+	registry := NewFileAliasRegistry(filepath.Join(t.TempDir(), "aliases.json"))
+	_ = registry.Set("runbook", "paste001")
+	client, _ := NewClient("username", "password", "token")
+	if _, err := client.Restore("paste001"); err == nil {
+		t.Error("should've returned an error, because no trash is configured")
+	}
+	client.WithTrash(trash).WithAliases(registry)
+	_ = client.DeletePaste("paste001")
+	newPasteKey, err := client.Restore("paste001")
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	paste := account.pastes[newPasteKey]
+	if paste == nil || paste.title != "runbook" || paste.content != "content" || paste.syntax != "markdown" || paste.visibility != int(VisibilityUnlisted) || paste.expiration != string(ExpirationNever) {
+		t.Errorf("expected the paste to have been recreated, got %+v", paste)
+	}
+	if _, err = trash.Get("paste001"); !errors.Is(err, ErrNotInTrash) {
+		t.Error("the paste should have been removed from the trash, got", err)
+	}
+	if pasteKey, _ := registry.Resolve("runbook"); pasteKey != newPasteKey {
+		t.Errorf("expected the alias to point to %s, got %s", newPasteKey, pasteKey)
+	}
+	if _, err = client.Restore("paste001"); !errors.Is(err, ErrNotInTrash) {
+		t.Error("expected ErrNotInTrash, got", err)
+	}
+}
+
+func TestClient_RestoreExpiredPaste(t *testing.T) {
+	account := newMockAccount()
+	httpClient = account.httpClient()
+	trash := NewTrash(t.TempDir())
+	_ = trash.put(&TrashedPaste{Key: "paste001", Title: "expired", Content: "content", ExpireDate: time.Now().Add(-time.Hour), DeletedAt: time.Now().Add(-2 * time.Hour)})
+	_ = trash.put(&TrashedPaste{Key: "paste002", Title: "expiring", Content: "content", ExpireDate: time.Now().Add(2 * time.Hour), DeletedAt: time.Now().Add(-time.Hour)})
+	client, _ := NewClient("username", "password", "token")
+	client.WithTrash(trash)
+	if _, err := client.Restore("paste001"); err == nil {
+		t.Error("should've returned an error, because the paste would have expired")
+	}
+	if _, err := trash.Get("paste001"); err != nil {
+		t.Error("a paste that cannot be restored should remain in the trash, got", err)
+	}
+	newPasteKey, err := client.Restore("paste002")
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if expiration := account.pastes[newPasteKey].expiration; expiration != string(ExpirationOneHour) {
+		t.Errorf("expected the expiration closest to the time left, got %s", expiration)
+	}
+	if trashedPastes, _ := trash.List(); len(trashedPastes) != 1 || trashedPastes[0].Key != "paste001" {
+		t.Errorf("unexpected pastes in the trash %v", trashedPastes)
+	}
+}
+
+func TestTrash_InvalidKey(t *testing.T) {
+	if _, err := NewTrash(t.TempDir()).Get("../secret"); !errors.Is(err, ErrInvalidPasteKey) {
+		t.Error("expected ErrInvalidPasteKey, got", err)
+	}
+}
@@ -1,6 +1,7 @@
 package pastebin
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -97,6 +98,20 @@ const (
 	VisibilityPrivate  Visibility = 2
 )
 
+// ParseVisibility parses the string representation of a Visibility (public, unlisted or private)
+func ParseVisibility(s string) (Visibility, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "public":
+		return VisibilityPublic, nil
+	case "unlisted":
+		return VisibilityUnlisted, nil
+	case "private":
+		return VisibilityPrivate, nil
+	default:
+		return 0, fmt.Errorf("unknown visibility %q", s)
+	}
+}
+
 func (v Visibility) String() string {
 	switch v {
 	case VisibilityPublic:
@@ -150,3 +165,94 @@ const (
 	ExpirationOneYear    Expiration = "1Y"
 	ExpirationNever      Expiration = "N"
 )
+
+// Expirations is the list of every supported Expiration, from the shortest to the longest
+var Expirations = []Expiration{
+	ExpirationTenMinutes,
+	ExpirationOneHour,
+	ExpirationOneDay,
+	ExpirationOneWeek,
+	ExpirationTwoWeeks,
+	ExpirationOneMonth,
+	ExpirationSixMonth,
+	ExpirationOneYear,
+	ExpirationNever,
+}
+
+var expirationDurations = map[Expiration]time.Duration{
+	ExpirationTenMinutes: 10 * time.Minute,
+	ExpirationOneHour:    time.Hour,
+	ExpirationOneDay:     24 * time.Hour,
+	ExpirationOneWeek:    7 * 24 * time.Hour,
+	ExpirationTwoWeeks:   14 * 24 * time.Hour,
+	ExpirationOneMonth:   30 * 24 * time.Hour,
+	ExpirationSixMonth:   182 * 24 * time.Hour,
+	ExpirationOneYear:    365 * 24 * time.Hour,
+}
+
+// Duration returns the approximate duration of the expiration, or 0 for ExpirationNever and unknown expirations
+func (e Expiration) Duration() time.Duration {
+	return expirationDurations[e]
+}
+
+// IsValid returns whether the expiration is one of the supported expirations
+func (e Expiration) IsValid() bool {
+	_, exists := expirationDurations[e]
+	return exists || e == ExpirationNever
+}
+
+// isLongerThan returns whether e expires after other, considering that ExpirationNever is longer than any expiration
+func (e Expiration) isLongerThan(other Expiration) bool {
+	if e == other || other == ExpirationNever {
+		return false
+	}
+	return e == ExpirationNever || e.Duration() > other.Duration()
+}
+
+// NearestExpiration returns the expiration whose duration is the closest to d, excluding ExpirationNever.
+// Returns ExpirationTenMinutes if d is 0 or less.
+func NearestExpiration(d time.Duration) Expiration {
+	nearest := ExpirationTenMinutes
+	for _, expiration := range Expirations {
+		if expiration == ExpirationNever {
+			continue
+		}
+		if (expiration.Duration() - d).Abs() < (nearest.Duration() - d).Abs() {
+			nearest = expiration
+		}
+	}
+	return nearest
+}
+
+// remainingExpiration returns the expiration to use for recreating a paste that expires at expireDate, which is
+// ExpirationNever if expireDate is the Unix epoch, or the expiration nearest to the time left otherwise
+func remainingExpiration(expireDate, now time.Time) Expiration {
+	if expireDate.Unix() <= 0 {
+		return ExpirationNever
+	}
+	return NearestExpiration(expireDate.Sub(now))
+}
+
+// minimumExpirationDurations are the shortest durations of the expirations, since months do not all last 30 days
+var minimumExpirationDurations = map[Expiration]time.Duration{
+	ExpirationOneMonth: 28 * 24 * time.Hour,
+	ExpirationSixMonth: 181 * 24 * time.Hour,
+}
+
+// ExpirationAtLeast returns the shortest expiration that cannot expire before d has elapsed, or ExpirationNever if d
+// is longer than every expiration
+func ExpirationAtLeast(d time.Duration) Expiration {
+	for _, expiration := range Expirations {
+		if expiration == ExpirationNever {
+			break
+		}
+		duration, exists := minimumExpirationDurations[expiration]
+		if !exists {
+			duration = expiration.Duration()
+		}
+		if duration >= d {
+			return expiration
+		}
+	}
+	return ExpirationNever
+}
@@ -124,3 +124,112 @@ func TestVisibility(t *testing.T) {
 		})
 	}
 }
+
+func TestNearestExpiration(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		duration time.Duration
+		want     Expiration
+	}{
+		{
+			desc:     "negative duration",
+			duration: -time.Hour,
+			want:     ExpirationTenMinutes,
+		},
+		{
+			desc:     "closer to ten minutes",
+			duration: 30 * time.Minute,
+			want:     ExpirationTenMinutes,
+		},
+		{
+			desc:     "closer to one hour",
+			duration: 50 * time.Minute,
+			want:     ExpirationOneHour,
+		},
+		{
+			desc:     "closer to two weeks",
+			duration: 12 * 24 * time.Hour,
+			want:     ExpirationTwoWeeks,
+		},
+		{
+			desc:     "longer than one year",
+			duration: 1000 * 24 * time.Hour,
+			want:     ExpirationOneYear,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			got := NearestExpiration(tC.duration)
+			if got != tC.want {
+				t.Errorf("expected expiration %s; got %s", tC.want, got)
+			}
+		})
+	}
+}
+
+func TestExpirationAtLeast(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		duration time.Duration
+		want     Expiration
+	}{
+		{
+			desc:     "zero duration",
+			duration: 0,
+			want:     ExpirationTenMinutes,
+		},
+		{
+			desc:     "exactly ten minutes",
+			duration: 10 * time.Minute,
+			want:     ExpirationTenMinutes,
+		},
+		{
+			desc:     "longer than one hour",
+			duration: 3 * time.Hour,
+			want:     ExpirationOneDay,
+		},
+		{
+			desc:     "just shorter than one week",
+			duration: 7*24*time.Hour - 1,
+			want:     ExpirationOneWeek,
+		},
+		{
+			desc:     "just longer than two weeks",
+			duration: 14*24*time.Hour + 1,
+			want:     ExpirationOneMonth,
+		},
+		{
+			desc:     "exactly one month",
+			duration: 28 * 24 * time.Hour,
+			want:     ExpirationOneMonth,
+		},
+		{
+			desc:     "just longer than one month",
+			duration: 29 * 24 * time.Hour,
+			want:     ExpirationSixMonth,
+		},
+		{
+			desc:     "longer than one month",
+			duration: 45 * 24 * time.Hour,
+			want:     ExpirationSixMonth,
+		},
+		{
+			desc:     "exactly one year",
+			duration: 365 * 24 * time.Hour,
+			want:     ExpirationOneYear,
+		},
+		{
+			desc:     "longer than one year",
+			duration: 400 * 24 * time.Hour,
+			want:     ExpirationNever,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			got := ExpirationAtLeast(tC.duration)
+			if got != tC.want {
+				t.Errorf("expected expiration %s; got %s", tC.want, got)
+			}
+		})
+	}
+}
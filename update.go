@@ -0,0 +1,93 @@
+package pastebin
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrPasteNotOwned = errors.New("paste does not belong to the authenticated user")
+)
+
+// UpdatePaste replaces the content of a paste owned by the authenticated user and returns the key of the new paste.
+//
+// Since Pastebin's API does not allow pastes to be edited, a new paste is created with the same title, syntax and
+// visibility, as well as the expiration closest to the time the paste had left, after which the old paste is deleted.
+// If the client has an AliasRegistry (see WithAliases), every alias of the old paste is updated to point to the new
+// paste before the old paste is deleted. Likewise, if the client has a RevisionHistory (see WithRevisionHistory) and
+// the old paste is the most recent revision of a document, the new paste is recorded as a new revision.
+//
+// The metadata of the old paste is retrieved from the list of the pastes of the user, so pastes that are not among the
+// MaxUserPastes most recent pastes of the user cannot be updated, in which case ErrPasteListTruncated is returned.
+//
+// If the old paste could not be deleted, the key of the new paste is returned along with the error.
+func (c *Client) UpdatePaste(pasteKey, content string) (string, error) {
+	paste, err := c.getUserPaste(pasteKey)
+	if err != nil {
+		return "", err
+	}
+	result, err := c.CreatePasteWithResult(NewCreatePasteRequest(paste.Title, content, remainingExpiration(paste.ExpireDate, time.Now()), paste.Visibility, paste.Syntax))
+	if err != nil {
+		return "", err
+	}
+	if c.aliases != nil {
+		if err = c.aliases.replacePasteKey(pasteKey, result.Key); err != nil {
+			return result.Key, fmt.Errorf("created paste %s but failed to update the aliases of paste %s: %w", result.Key, pasteKey, err)
+		}
+	}
+	if c.history != nil {
+		if err = c.recordUpdate(pasteKey, result.Key, result.content); err != nil {
+			return result.Key, fmt.Errorf("created paste %s but failed to record it in the revision history: %w", result.Key, err)
+		}
+	}
+	if err = c.deletePaste(pasteKey, paste); err != nil {
+		return result.Key, fmt.Errorf("created paste %s but failed to delete paste %s: %w", result.Key, pasteKey, err)
+	}
+	return result.Key, nil
+}
+
+// getUserPaste returns the metadata of a paste owned by the authenticated user, or ErrPasteNotOwned if none of the
+// MaxUserPastes most recent pastes of the user has the key. If the list may be truncated, ErrPasteListTruncated is returned
+// instead, since the paste may still belong to the user.
+func (c *Client) getUserPaste(pasteKey string) (*Paste, error) {
+	pastes, truncated, err := c.getAllUserPastes()
+	if err != nil {
+		return nil, err
+	}
+	for _, paste := range pastes {
+		if paste.Key == pasteKey {
+			return paste, nil
+		}
+	}
+	if truncated {
+		return nil, fmt.Errorf("%w: paste %s is not among the %d most recent pastes", ErrPasteListTruncated, pasteKey, MaxUserPastes)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPasteNotOwned, pasteKey)
+}
+
+// invalidPermissionToViewPaste is the response of Pastebin when the paste does not exist or does not belong to the
+// authenticated user
+const invalidPermissionToViewPaste = "Bad API request, invalid permission to view this paste or invalid api_paste_key"
+
+// getUserPasteContent retrieves the content of a paste with GetUserPasteContent, returning ErrPasteNotOwned if
+// Pastebin refuses to show it because it only shows the pastes of the authenticated user. Other errors, such as an
+// invalid api_dev_key, are returned as is.
+func (c *Client) getUserPasteContent(pasteKey string) (string, error) {
+	content, err := c.GetUserPasteContent(pasteKey)
+	if err != nil && err.Error() == invalidPermissionToViewPaste {
+		return "", fmt.Errorf("%w: %s", ErrPasteNotOwned, pasteKey)
+	}
+	return content, err
+}
+
+// recordUpdate records the paste that replaced oldPasteKey as a new revision of the document oldPasteKey was the most
+// recent revision of, if any
+func (c *Client) recordUpdate(oldPasteKey, newPasteKey, content string) error {
+	document, exists, err := c.history.documentOf(oldPasteKey)
+	if err != nil || !exists {
+		return err
+	}
+	_, err = c.history.Record(document, newPasteKey, content, c.username)
+	return err
+}
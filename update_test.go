@@ -0,0 +1,112 @@
+package pastebin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func TestClient_UpdatePaste(t *testing.T) {
+	account := newMockAccount()
+	account.pastes["paste001"] = &mockAccountPaste{title: "runbook", content: "v1", syntax: "markdown", visibility: int(VisibilityUnlisted), date: 1600000000}
+	httpClient = account.httpClient()
+	registry := NewFileAliasRegistry(filepath.Join(t.TempDir(), "aliases.json"))
+	_ = registry.Set("runbook", "paste001")
+	_ = registry.Set("other", "paste999")
+	client, _ := NewClient("username", "password", "token")
+	client.WithAliases(registry)
+	newPasteKey, err := client.UpdatePaste("paste001", "v2")
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if _, exists := account.pastes["paste001"]; exists {
+		t.Error("the old paste should have been deleted")
+	}
+	paste := account.pastes[newPasteKey]
+	if paste == nil {
+		t.Fatalf("expected paste %s to have been created", newPasteKey)
+	}
+	if paste.title != "runbook" || paste.content != "v2" || paste.syntax != "markdown" || paste.visibility != int(VisibilityUnlisted) || paste.expiration != string(ExpirationNever) {
+		t.Errorf("the metadata of the paste should have been preserved, got %+v", paste)
+	}
+	if pasteKey, _ := registry.Resolve("runbook"); pasteKey != newPasteKey {
+		t.Errorf("expected the alias to point to %s, got %s", newPasteKey, pasteKey)
+	}
+	if pasteKey, _ := registry.Resolve("other"); pasteKey != "paste999" {
+		t.Errorf("other aliases shouldn't have been modified, got %s", pasteKey)
+	}
+	if _, err = client.UpdatePaste("paste001", "v3"); !errors.Is(err, ErrPasteNotOwned) {
+		t.Error("expected ErrPasteNotOwned, got", err)
+	}
+	if len(account.pastes) != 1 {
+		t.Error("no paste should have been created for a paste that isn't owned by the user")
+	}
+}
+
+func TestClient_UpdatePasteWithTruncatedList(t *testing.T) {
+	account := newMockAccount()
+	for i := 0; i < MaxUserPastes; i++ {
+		account.pastes[fmt.Sprintf("paste%04d", i)] = &mockAccountPaste{title: "paste", content: "content", date: 1600000000}
+	}
+	account.pastes["oldpaste"] = &mockAccountPaste{title: "old", content: "v1", date: 1500000000}
+	account.unlisted["oldpaste"] = true
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	if _, err := client.UpdatePaste("oldpaste", "v2"); !errors.Is(err, ErrPasteListTruncated) {
+		t.Error("expected ErrPasteListTruncated, got", err)
+	}
+	if account.created != 0 {
+		t.Error("no paste should have been created")
+	}
+}
+
+func TestClient_UpdateAlias(t *testing.T) {
+	account := newMockAccount()
+	account.pastes["paste001"] = &mockAccountPaste{title: "runbook", content: "v1", syntax: "text", date: 1600000000}
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	if _, err := client.UpdateAlias("runbook", "v2"); err == nil {
+		t.Error("should've returned an error, because no alias registry is configured")
+	}
+	registry := NewFileAliasRegistry(filepath.Join(t.TempDir(), "aliases.json"))
+	_ = registry.Set("runbook", "paste001")
+	client.WithAliases(registry)
+	newPasteKey, err := client.UpdateAlias("runbook", "v2")
+	if err != nil {
+		t.Fatal("shouldn't have returned an error, got", err)
+	}
+	if account.pastes[newPasteKey] == nil || account.pastes[newPasteKey].content != "v2" {
+		t.Error("expected the paste to have been updated")
+	}
+	if _, err = client.UpdateAlias("unknown", "v3"); !errors.Is(err, ErrAliasNotFound) {
+		t.Error("expected ErrAliasNotFound, got", err)
+	}
+}
+
+func TestClient_getUserPasteContentWithOtherAPIError(t *testing.T) {
+	account := newMockAccount()
+	account.pastes["paste001"] = &mockAccountPaste{title: "runbook", content: "v1"}
+	account.failKeys["paste001"] = true
+	httpClient = account.httpClient()
+	client, _ := NewClient("username", "password", "token")
+	if _, err := client.getUserPasteContent("paste001"); !errors.Is(err, ErrPasteNotOwned) {
+		t.Error("expected ErrPasteNotOwned, got", err)
+	}
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		body := "session-key"
+		if request.URL.String() != LoginApiUrl {
+			body = "Bad API request, invalid api_dev_key"
+		}
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewBufferString(body))}
+	})}
+	_, err := client.getUserPasteContent("paste001")
+	if err == nil || errors.Is(err, ErrPasteNotOwned) || err.Error() != "Bad API request, invalid api_dev_key" {
+		t.Error("expected the API error to be returned as is, got", err)
+	}
+}
@@ -0,0 +1,67 @@
+package pastebin
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidCreatePasteRequest is matched by every error returned by CreatePasteRequest.Validate
+	ErrInvalidCreatePasteRequest = errors.New("invalid create paste request")
+)
+
+// ClientCapabilities describes what a client is able to do, which affects which requests are valid
+type ClientCapabilities struct {
+	// Authenticated is whether the client was configured with a username and a password, which is required to
+	// create private pastes
+	Authenticated bool
+}
+
+// Capabilities returns the capabilities of the client
+func (c *Client) Capabilities() ClientCapabilities {
+	return ClientCapabilities{Authenticated: len(c.getSessionKey()) > 0}
+}
+
+// FieldError is an error caused by the value of a field of a request
+type FieldError struct {
+	// Field is the name of the field, as named in the request struct
+	Field string
+
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Err.Error())
+}
+
+// Unwrap returns the underlying error, which allows errors.Is(err, ErrNotAuthenticated) to match an invalid visibility
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Is allows errors.Is(err, ErrInvalidCreatePasteRequest) to match a *FieldError
+func (e *FieldError) Is(target error) bool {
+	return target == ErrInvalidCreatePasteRequest
+}
+
+// Validate returns every problem with the request at once, as *FieldError joined with errors.Join, or nil if the
+// request is valid for a client with the given capabilities.
+//
+// The individual errors can be retrieved by checking whether the error implements interface{ Unwrap() []error }.
+// CreatePaste calls Validate automatically before sending the request.
+func (r *CreatePasteRequest) Validate(capabilities ClientCapabilities) error {
+	var errs []error
+	if len(r.Code) == 0 {
+		errs = append(errs, &FieldError{Field: "Code", Err: errors.New("must not be empty")})
+	}
+	if len(r.Expiration) > 0 && !r.Expiration.IsValid() {
+		errs = append(errs, &FieldError{Field: "Expiration", Err: fmt.Errorf("unknown expiration %q", r.Expiration)})
+	}
+	switch {
+	case r.Visibility.String() == "unknown":
+		errs = append(errs, &FieldError{Field: "Visibility", Err: fmt.Errorf("unknown visibility %d", r.Visibility)})
+	case r.Visibility == VisibilityPrivate && !capabilities.Authenticated:
+		errs = append(errs, &FieldError{Field: "Visibility", Err: ErrNotAuthenticated})
+	}
+	return errors.Join(errs...)
+}
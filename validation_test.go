@@ -0,0 +1,85 @@
+package pastebin
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/TwiN/go-pastebin/test"
+)
+
+func TestCreatePasteRequest_Validate(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		request        *CreatePasteRequest
+		capabilities   ClientCapabilities
+		expectedFields []string
+	}{
+		{
+			desc:         "valid-request",
+			request:      NewCreatePasteRequest("", "content", ExpirationTenMinutes, VisibilityPublic, ""),
+			capabilities: ClientCapabilities{},
+		},
+		{
+			desc:         "valid-request-without-expiration",
+			request:      NewCreatePasteRequest("", "content", "", VisibilityUnlisted, ""),
+			capabilities: ClientCapabilities{},
+		},
+		{
+			desc:         "valid-private-request",
+			request:      NewCreatePasteRequest("", "content", ExpirationNever, VisibilityPrivate, ""),
+			capabilities: ClientCapabilities{Authenticated: true},
+		},
+		{
+			desc:           "private-request-on-guest-client",
+			request:        NewCreatePasteRequest("", "content", ExpirationNever, VisibilityPrivate, ""),
+			capabilities:   ClientCapabilities{},
+			expectedFields: []string{"Visibility"},
+		},
+		{
+			desc:           "every-field-invalid",
+			request:        NewCreatePasteRequest("", "", "2Y", Visibility(7), ""),
+			capabilities:   ClientCapabilities{Authenticated: true},
+			expectedFields: []string{"Code", "Expiration", "Visibility"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := tC.request.Validate(tC.capabilities)
+			if len(tC.expectedFields) == 0 {
+				if err != nil {
+					t.Fatal("shouldn't have returned an error, got", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidCreatePasteRequest) {
+				t.Fatalf("expected %v, got %v", ErrInvalidCreatePasteRequest, err)
+			}
+			joinedErr, ok := err.(interface{ Unwrap() []error })
+			if !ok {
+				t.Fatalf("expected a joined error, got %T", err)
+			}
+			if len(joinedErr.Unwrap()) != len(tC.expectedFields) {
+				t.Fatalf("expected %d errors, got %d: %v", len(tC.expectedFields), len(joinedErr.Unwrap()), err)
+			}
+			for i, fieldErr := range joinedErr.Unwrap() {
+				var e *FieldError
+				if !errors.As(fieldErr, &e) || e.Field != tC.expectedFields[i] {
+					t.Errorf("expected error for field %s, got %v", tC.expectedFields[i], fieldErr)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_CreatePasteWithInvalidRequest(t *testing.T) {
+	httpClient = &http.Client{Transport: test.MockRoundTripper(func(request *http.Request) *http.Response {
+		t.Fatal("no request should have been sent for an invalid request")
+		return nil
+	})}
+	client, _ := NewClient("", "", "token")
+	_, err := client.CreatePaste(NewCreatePasteRequest("", "", "2Y", VisibilityPrivate, ""))
+	if !errors.Is(err, ErrInvalidCreatePasteRequest) || !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("expected an error matching both %v and %v, got %v", ErrInvalidCreatePasteRequest, ErrNotAuthenticated, err)
+	}
+}
@@ -0,0 +1,30 @@
+package pastebin
+
+import "sync"
+
+// DefaultConcurrency is the number of concurrent requests used by bulk operations when no concurrency is specified
+const DefaultConcurrency = 4
+
+// runConcurrently calls fn for every index from 0 to n-1, with at most concurrency calls running at the same time.
+// A concurrency of 0 or less defaults to DefaultConcurrency. Returns once every call has returned.
+func runConcurrently(n, concurrency int, fn func(i int)) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	indexes := make(chan int)
+	var waitGroup sync.WaitGroup
+	for worker := 0; worker < min(concurrency, n); worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	waitGroup.Wait()
+}
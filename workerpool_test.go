@@ -0,0 +1,39 @@
+package pastebin
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunConcurrently(t *testing.T) {
+	var running, maxRunning atomic.Int64
+	var mutex sync.Mutex
+	called := make(map[int]int)
+	runConcurrently(20, 3, func(i int) {
+		current := running.Add(1)
+		for {
+			previous := maxRunning.Load()
+			if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		mutex.Lock()
+		called[i]++
+		mutex.Unlock()
+	})
+	if len(called) != 20 {
+		t.Errorf("expected fn to be called for 20 indexes, got %d", len(called))
+	}
+	for i, count := range called {
+		if count != 1 {
+			t.Errorf("expected fn to be called once for index %d, got %d", i, count)
+		}
+	}
+	if maxRunning.Load() > 3 {
+		t.Errorf("expected at most 3 concurrent calls, got %d", maxRunning.Load())
+	}
+}